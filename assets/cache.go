@@ -1,6 +1,14 @@
 package assets
 
 import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"sync"
+	"sync/atomic"
+
 	"github.com/hulutech-web/goravel-captcha/assets/fonts"
 	"github.com/hulutech-web/goravel-captcha/assets/images"
 )
@@ -10,9 +18,20 @@ type AssetData struct {
 	Path string
 	// 内容
 	Content []byte
+	// 解析后的字体，按集合中的序号缓存，首次使用时解析
+	fonts map[int]*Font
+	// 解码后的图片，首次使用时解码
+	image image.Image
 }
 
-var cache []*AssetData
+// 以路径为键的资源缓存
+var cache = make(map[string]*AssetData)
+
+// 并发生成验证码时保护缓存读写
+var mu sync.RWMutex
+
+// 缓存版本，资源被替换或清除时递增，依赖资源内容的结果按版本缓存
+var version atomic.Uint64
 
 var defaultAssetsImage = []string{
 	"assets/images/1.jpg",
@@ -70,33 +89,120 @@ func DefaultBinImageList() []string {
  * @return error
  */
 func GetAssetCache(path string) (ret []byte, erro error) {
-	if len(cache) > 0 {
-		for _, asset := range cache {
-			if asset.Path == path {
-				ret = asset.Content
-				return
-			}
+	asset, erro := getAsset(path)
+	if asset != nil {
+		ret = asset.Content
+	}
+	return
+}
+
+// GetAssetFont is a function
+/**
+ * @Description: 获取缓存资源解析后的字体，同一路径只解析一次，字体集合通过 "路径#序号" 指定其中的字体
+ * @param path
+ * @return *Font
+ * @return error
+ */
+func GetAssetFont(path string) (*Font, error) {
+	file, index := SplitFontPath(path)
+	asset, err := getAsset(file)
+	if asset == nil {
+		return nil, err
+	}
+
+	mu.RLock()
+	f := asset.fonts[index]
+	mu.RUnlock()
+	if f != nil {
+		return f, nil
+	}
+
+	f, err = ParseFont(asset.Content, index)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	// 解析期间缓存可能已被清除或替换，只写回仍在缓存中的资源
+	if cache[file] == asset {
+		if asset.fonts == nil {
+			asset.fonts = make(map[int]*Font)
 		}
+		asset.fonts[index] = f
+	}
+	mu.Unlock()
+	return f, nil
+}
+
+// GetAssetImage is a function
+/**
+ * @Description: 获取缓存资源解码后的图片，同一路径只解码一次，调用方只读
+ * @param path
+ * @return image.Image
+ * @return error
+ */
+func GetAssetImage(path string) (image.Image, error) {
+	asset, err := getAsset(path)
+	if asset == nil {
+		return nil, err
 	}
 
-	ret, erro = findFontsAsset(path)
-	if len(ret) > 0 {
-		cache = append(cache, &AssetData{
-			Path:    path,
-			Content: ret,
-		})
-		return
+	mu.RLock()
+	img := asset.image
+	mu.RUnlock()
+	if img != nil {
+		return img, nil
 	}
 
-	ret, erro = findImagesAsset(path)
-	if len(ret) > 0 {
-		cache = append(cache, &AssetData{
-			Path:    path,
-			Content: ret,
-		})
-		return
+	img, _, err = image.Decode(bytes.NewReader(asset.Content))
+	if err != nil {
+		return nil, err
 	}
-	return
+
+	mu.Lock()
+	if cache[path] == asset {
+		asset.image = img
+	}
+	mu.Unlock()
+	return img, nil
+}
+
+/**
+ * @Description: 获取缓存资源，未缓存时从内置资源加载
+ * @param path
+ * @return *AssetData
+ * @return error
+ */
+func getAsset(path string) (*AssetData, error) {
+	mu.RLock()
+	asset, ok := cache[path]
+	mu.RUnlock()
+	if ok {
+		return asset, nil
+	}
+
+	ret, err := findFontsAsset(path)
+	if len(ret) == 0 {
+		ret, err = findImagesAsset(path)
+	}
+	if len(ret) == 0 {
+		if err == nil {
+			err = fmt.Errorf("Asset %s not found", path)
+		}
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if asset, ok = cache[path]; ok {
+		return asset, nil
+	}
+	asset = &AssetData{
+		Path:    path,
+		Content: ret,
+	}
+	cache[path] = asset
+	return asset, nil
 }
 
 // HasAssetCache is a function
@@ -106,54 +212,62 @@ func GetAssetCache(path string) (ret []byte, erro error) {
  * @return bool
  */
 func HasAssetCache(path string) bool {
-	if len(cache) > 0 {
-		for _, asset := range cache {
-			if asset.Path == path {
-				return true
-			}
-		}
-	}
-	return false
+	path, _ = SplitFontPath(path)
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := cache[path]
+	return ok
 }
 
 // ClearAssetCache is a function
 /**
- * @Description: 清除资源缓存
+ * @Description: 清除资源缓存，同时清除已解析的字体和已解码的图片
  * @param paths
  * @return bool
  */
 func ClearAssetCache(paths []string) bool {
-	if len(cache) > 0 {
-		for _, path := range paths {
-			for ak, asset := range cache {
-				if asset.Path == path {
-					cache = append(cache[:ak], cache[(ak+1):]...)
-					break
-				}
-			}
-		}
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, path := range paths {
+		path, _ = SplitFontPath(path)
+		delete(cache, path)
 	}
+	version.Add(1)
 	return true
 }
 
 // SetAssetCache is a function
 /**
- * @Description: 设置缓存资源
+ * @Description: 设置缓存资源，force 为 true 时替换已有资源并丢弃其解析结果
  * @param path
  * @return error
  */
 func SetAssetCache(path string, content []byte, force bool) bool {
-	if len(cache) > 0 {
-		for _, asset := range cache {
-			if asset.Path == path && !force {
-				return true
-			}
-		}
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, ok := cache[path]
+	if ok && !force {
+		return true
 	}
 
-	cache = append(cache, &AssetData{
+	cache[path] = &AssetData{
 		Path:    path,
 		Content: content,
-	})
+	}
+	if ok {
+		version.Add(1)
+	}
 	return true
 }
+
+// Version is a function
+/**
+ * @Description: 缓存版本，已有资源被替换或清除后改变
+ * @return uint64
+ */
+func Version() uint64 {
+	return version.Load()
+}
@@ -0,0 +1,99 @@
+package assets
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(0, 0, c)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAssetImageCache(t *testing.T) {
+	path := "test/cache.png"
+	t.Cleanup(func() { ClearAssetCache([]string{path}) })
+
+	SetAssetCache(path, testPNG(t, color.White), false)
+	a, err := GetAssetImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一路径只解码一次
+	if b, _ := GetAssetImage(path); b != a {
+		t.Fatal("GetAssetImage() decoded the cached image again")
+	}
+
+	// 不强制刷新时保留已有资源
+	SetAssetCache(path, testPNG(t, color.Black), false)
+	if b, _ := GetAssetImage(path); b != a {
+		t.Fatal("SetAssetCache() without force replaced the image")
+	}
+
+	// 强制刷新时丢弃已解码的图片
+	SetAssetCache(path, testPNG(t, color.Black), true)
+	b, err := GetAssetImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b == a {
+		t.Fatal("SetAssetCache() with force kept the old decoded image")
+	}
+	if _, _, _, a := b.At(0, 0).RGBA(); a != 0xffff {
+		t.Fatal("GetAssetImage() did not decode the new content")
+	}
+
+	ClearAssetCache([]string{path})
+	if HasAssetCache(path) {
+		t.Fatal("ClearAssetCache() kept the asset")
+	}
+	if _, err := GetAssetImage(path); err == nil {
+		t.Fatal("GetAssetImage() after ClearAssetCache() returned an image")
+	}
+}
+
+func TestAssetFontCache(t *testing.T) {
+	path := DefaultBinFontList()[0]
+	a, err := GetAssetFont(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := GetAssetFont(path); b != a {
+		t.Fatal("GetAssetFont() parsed the cached font again")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	path := "test/version.png"
+	t.Cleanup(func() { ClearAssetCache([]string{path}) })
+
+	v := Version()
+	// 新增资源不影响已有的结果
+	SetAssetCache(path, testPNG(t, color.White), false)
+	if Version() != v {
+		t.Fatal("SetAssetCache() of a new path changed the version")
+	}
+	SetAssetCache(path, testPNG(t, color.Black), false)
+	if Version() != v {
+		t.Fatal("SetAssetCache() without force changed the version")
+	}
+
+	SetAssetCache(path, testPNG(t, color.Black), true)
+	if Version() == v {
+		t.Fatal("SetAssetCache() replacing an asset kept the version")
+	}
+	v = Version()
+	ClearAssetCache([]string{path})
+	if Version() == v {
+		t.Fatal("ClearAssetCache() kept the version")
+	}
+}
@@ -0,0 +1,127 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// 字体集合中字体序号的分隔符，例如 "fonts/NotoSansCJK.ttc#2"
+const fontIndexSep = "#"
+
+// Font is a type
+/**
+ * @Description: 解析后的字体。TrueType 轮廓的单个字体沿用 freetype 解析和渲染，
+ * CFF 轮廓的 OTF 及 TTC/OTC 字体集合使用 x/image/font/opentype
+ */
+type Font struct {
+	// TrueType 轮廓的单个字体
+	TrueType *truetype.Font
+	// OTF 或字体集合中的字体
+	OpenType *opentype.Font
+}
+
+// SplitFontPath is a function
+/**
+ * @Description: 拆分字体路径及集合中的字体序号，没有序号时为 0
+ * @param path	例如 "fonts/a.ttf"、"fonts/b.ttc#1"
+ * @return string	文件路径
+ * @return int		字体序号
+ */
+func SplitFontPath(path string) (string, int) {
+	i := strings.LastIndex(path, fontIndexSep)
+	if i < 0 {
+		return path, 0
+	}
+	index, err := strconv.Atoi(path[i+1:])
+	if err != nil || index < 0 {
+		return path, 0
+	}
+	return path[:i], index
+}
+
+// ParseFont is a function
+/**
+ * @Description: 解析字体文件，字体集合按序号取出其中的字体
+ * @param content
+ * @param index
+ * @return *Font
+ * @return error
+ */
+func ParseFont(content []byte, index int) (*Font, error) {
+	// 字体集合以 "ttcf" 开头，单个 OTF 字体以 "OTTO" 开头
+	if !bytes.HasPrefix(content, []byte("ttcf")) {
+		if index != 0 {
+			return nil, fmt.Errorf("the font is not a collection, index %d is invalid", index)
+		}
+		if !bytes.HasPrefix(content, []byte("OTTO")) {
+			f, err := truetype.Parse(content)
+			if err != nil {
+				return nil, err
+			}
+			return &Font{TrueType: f}, nil
+		}
+	}
+
+	c, err := opentype.ParseCollection(content)
+	if err != nil {
+		return nil, err
+	}
+	if index >= c.NumFonts() {
+		return nil, fmt.Errorf("the font collection has %d fonts, index %d is out of range", c.NumFonts(), index)
+	}
+	f, err := c.Font(index)
+	if err != nil {
+		return nil, err
+	}
+	return &Font{OpenType: f}, nil
+}
+
+// HasGlyph is a function
+/**
+ * @Description: 字体是否包含字符的字形，字形索引 0 为 .notdef 即缺少该字形
+ * @receiver f
+ * @param r
+ * @return bool
+ */
+func (f *Font) HasGlyph(r rune) bool {
+	if f.TrueType != nil {
+		return f.TrueType.Index(r) != 0
+	}
+	var buf sfnt.Buffer
+	idx, err := f.OpenType.GlyphIndex(&buf, r)
+	return err == nil && idx != 0
+}
+
+// NewFace is a function
+/**
+ * @Description: 创建指定字号的 font.Face，Face 不能并发使用
+ * @receiver f
+ * @param size
+ * @param dpi
+ * @param hinting
+ * @return font.Face
+ * @return error
+ */
+func (f *Font) NewFace(size, dpi float64, hinting font.Hinting) (font.Face, error) {
+	if f.TrueType != nil {
+		// TrueType 字体由 freetype 直接绘制，Face 只用于度量，字形缓存取最小值
+		return truetype.NewFace(f.TrueType, &truetype.Options{
+			Size:              size,
+			DPI:               dpi,
+			Hinting:           hinting,
+			GlyphCacheEntries: 1,
+		}), nil
+	}
+	return opentype.NewFace(f.OpenType, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     dpi,
+		Hinting: hinting,
+	})
+}
@@ -0,0 +1,104 @@
+package assets
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// 用同一个 TrueType 字体组成包含 n 个字体的集合，表的偏移按集合头部长度平移
+func testCollection(ttf []byte, n int) []byte {
+	head := 12 + 4*n
+	out := make([]byte, head, head+len(ttf))
+	copy(out, "ttcf")
+	binary.BigEndian.PutUint32(out[4:], 0x00010000)
+	binary.BigEndian.PutUint32(out[8:], uint32(n))
+	for i := 0; i < n; i++ {
+		binary.BigEndian.PutUint32(out[12+4*i:], uint32(head))
+	}
+	out = append(out, ttf...)
+	font := out[head:]
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := font[12+16*i:]
+		binary.BigEndian.PutUint32(rec[8:], binary.BigEndian.Uint32(rec[8:])+uint32(head))
+	}
+	return out
+}
+
+func TestSplitFontPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		file  string
+		index int
+	}{
+		{"a.ttf", "a.ttf", 0},
+		{"a.ttc#1", "a.ttc", 1},
+		{"fonts/#x/a.ttc#12", "fonts/#x/a.ttc", 12},
+		{"a.ttc#x", "a.ttc#x", 0},
+		{"a.ttc#-1", "a.ttc#-1", 0},
+	}
+	for _, tt := range tests {
+		file, index := SplitFontPath(tt.path)
+		if file != tt.file || index != tt.index {
+			t.Errorf("SplitFontPath(%q) = %q, %d; want %q, %d", tt.path, file, index, tt.file, tt.index)
+		}
+	}
+}
+
+func TestParseFont(t *testing.T) {
+	f, err := ParseFont(goregular.TTF, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.TrueType == nil || !f.HasGlyph('A') || f.HasGlyph('Ꭰ') {
+		t.Fatalf("ParseFont(ttf) = %+v, want a TrueType font with [A] and without [Ꭰ]", f)
+	}
+	if _, err := ParseFont(goregular.TTF, 1); err == nil || !strings.Contains(err.Error(), "not a collection") {
+		t.Errorf("ParseFont(ttf, 1) = %v, want a not a collection error", err)
+	}
+
+	ttc := testCollection(goregular.TTF, 2)
+	f, err = ParseFont(ttc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.OpenType == nil || !f.HasGlyph('A') || f.HasGlyph('Ꭰ') {
+		t.Fatalf("ParseFont(ttc, 1) = %+v, want an OpenType font with [A] and without [Ꭰ]", f)
+	}
+	face, err := f.NewFace(32, 72, font.HintingFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer face.Close()
+	if adv := font.MeasureString(face, "W"); adv <= 0 {
+		t.Errorf("MeasureString(W) = %v, want a positive advance", adv)
+	}
+	if _, err := ParseFont(ttc, 2); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("ParseFont(ttc, 2) = %v, want an out of range error", err)
+	}
+}
+
+func TestAssetFontCollection(t *testing.T) {
+	path := "test/collection.ttc"
+	t.Cleanup(func() { ClearAssetCache([]string{path}) })
+	SetAssetCache(path, testCollection(goregular.TTF, 2), false)
+
+	a, err := GetAssetFont(path + "#1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每个序号单独解析并缓存
+	if b, _ := GetAssetFont(path + "#1"); b != a {
+		t.Error("GetAssetFont() parsed the same font again")
+	}
+	if b, _ := GetAssetFont(path); b == a || b == nil {
+		t.Errorf("GetAssetFont(%s) = %p, want a separate font from index 1", path, b)
+	}
+	if _, err := GetAssetFont(path + "#5"); err == nil {
+		t.Error("GetAssetFont(#5) returned no error")
+	}
+}
@@ -7,6 +7,88 @@ import (
 func init() {
 	config := facades.Config()
 	config.Add("captcha", map[string]any{
-		
+		// 内置字符集，多个用逗号分隔：simplified、traditional、latin、digits、kana，为空时使用默认字符
+		"charset": config.Env("CAPTCHA_CHARSET", ""),
+		// 字体缺少字符字形时的处理方式：restrict 字符只使用包含其字形的字体，strict 启动时报告缺失的字符
+		"glyph_coverage": config.Env("CAPTCHA_GLYPH_COVERAGE", "restrict"),
+		// 验证文本：random 随机字符，phrase 按阅读顺序点选词语或成语，icon 按顺序点选图标
+		"text_mode": config.Env("CAPTCHA_TEXT_MODE", "random"),
+		// 词语模式的词库文件，每行一个词语，为空时使用内置成语
+		"phrase_file": config.Env("CAPTCHA_PHRASE_FILE", ""),
+		// 图标模式的图标目录，透明背景的 PNG，文件名为图标名称
+		"icon_dir": config.Env("CAPTCHA_ICON_DIR", ""),
+		// 图标目录中图标文件的匹配规则
+		"icon_pattern": config.Env("CAPTCHA_ICON_PATTERN", "*.png"),
+		// 主图文本排布方式：random 随机，saliency 避开背景中细节多或与文本颜色相近的区域
+		"text_placement": config.Env("CAPTCHA_TEXT_PLACEMENT", "random"),
+		// 主图文本与背景的最低对比度 1-21，如 4.5，0 为不按背景调整文本颜色
+		"text_contrast": config.Env("CAPTCHA_TEXT_CONTRAST", "0"),
+		// 默认配色：default、dark、deuteranopia、protanopia
+		"theme": config.Env("CAPTCHA_THEME", "default"),
+		// 客户端可以通过 ?theme= 请求的配色，多个用逗号分隔
+		"themes": config.Env("CAPTCHA_THEMES", "default,dark,deuteranopia,protanopia"),
+		// 主图干扰层，颜色与文本一致，数量为 0 时不绘制
+		"interference": map[string]any{
+			// 穿过文本区域的波浪线数量
+			"wave_lines": config.Env("CAPTCHA_INTERFERENCE_WAVE_LINES", 0),
+			// 随机弧线数量
+			"arcs": config.Env("CAPTCHA_INTERFERENCE_ARCS", 0),
+			// 噪点数量
+			"noise_dots": config.Env("CAPTCHA_INTERFERENCE_NOISE_DOTS", 0),
+			// 与文本笔画相似的干扰笔画数量
+			"decoy_strokes": config.Env("CAPTCHA_INTERFERENCE_DECOY_STROKES", 0),
+			// 透明度 0-1
+			"alpha": config.Env("CAPTCHA_INTERFERENCE_ALPHA", "0.8"),
+		},
+		// 本地背景图目录，为空时使用模块内置的背景图，不依赖网络
+		"background_dir": config.Env("CAPTCHA_BACKGROUND_DIR", ""),
+		// 背景图目录下的文件匹配规则
+		"background_pattern": config.Env("CAPTCHA_BACKGROUND_PATTERN", "*.jpg"),
+		// 背景来源：photo 背景图片，procedural 程序生成（渐变、噪声、多边形、条纹），mixed 两者随机混合
+		"background_mode": config.Env("CAPTCHA_BACKGROUND_MODE", "photo"),
+		// 背景增强强度 0-1，随机翻转、调整色相饱和度亮度、模糊或锐化并加入噪声，0 为不处理
+		"background_augment": config.Env("CAPTCHA_BACKGROUND_AUGMENT", "0"),
+		// 预渲染池，后台协程提前渲染验证码，降低请求耗时
+		"pool": map[string]any{
+			// 是否启用
+			"enabled": config.Env("CAPTCHA_POOL_ENABLED", false),
+			// 池中保留的验证码数量
+			"size": config.Env("CAPTCHA_POOL_SIZE", 50),
+			// 后台渲染协程数量
+			"workers": config.Env("CAPTCHA_POOL_WORKERS", 2),
+			// 验证码最大存活时间（秒），超时后重新渲染
+			"max_age": config.Env("CAPTCHA_POOL_MAX_AGE", 300),
+			// 收到退出信号后等待后台协程结束的最长时间（秒）
+			"shutdown_timeout": config.Env("CAPTCHA_POOL_SHUTDOWN_TIMEOUT", 5),
+		},
+		// 自适应难度，同一IP或会话连续失败后提高下一个验证码的难度，随时间衰减
+		"adaptive": map[string]any{
+			// 是否启用
+			"enabled": config.Env("CAPTCHA_ADAPTIVE_ENABLED", false),
+			// 失败分数的半衰期（秒）
+			"half_life": config.Env("CAPTCHA_ADAPTIVE_HALF_LIFE", 600),
+		},
+		// 命名配置，通过 ?profile=login 或 api/captcha/login 选择，验证码只能在签发它的配置下校验
+		// 未设置的项沿用默认配置，ttl 为验证码有效期（秒），charset 同上
+		"profiles": map[string]any{
+			"login": map[string]any{
+				"check_text_len_min": 2,
+				"check_text_len_max": 3,
+				"image_font_distort": 2,
+				"ttl":                120,
+			},
+			"register": map[string]any{
+				"check_text_len_min": 3,
+				"check_text_len_max": 4,
+				"image_font_distort": 3,
+				"ttl":                300,
+			},
+			"comment": map[string]any{
+				"check_text_len_min": 2,
+				"check_text_len_max": 2,
+				"image_font_distort": 1,
+				"ttl":                600,
+			},
+		},
 	})
 }
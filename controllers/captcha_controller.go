@@ -12,11 +12,43 @@ func NewCaptchaController() *CaptchaController {
 	return &CaptchaController{}
 }
 
+// profile 路径参数优先，其次为 ?profile=，都为空时使用默认配置
+func (c *CaptchaController) profile(ctx http.Context) string {
+	if name := ctx.Request().Route("profile"); name != "" {
+		return name
+	}
+	return ctx.Request().Query("profile", Capt.DefaultProfile)
+}
+
+// identity 来源标识，有会话时使用会话ID，否则使用IP
+func (c *CaptchaController) identity(ctx http.Context) string {
+	if ctx.Request().HasSession() {
+		return ctx.Request().Session().GetID()
+	}
+	return ctx.Request().Ip()
+}
+
 func (c *CaptchaController) GetCaptcha(ctx http.Context) http.Response {
-	dots, b64, tb64, key, err := Capt.MakeCaptcha()
+	name := c.profile(ctx)
+	if _, ok := Capt.GetProfile(name); !ok {
+		return ctx.Response().Json(http.StatusNotFound, http.Json{
+			"error": "验证码配置不存在",
+		})
+	}
+
+	// 配色只能从允许列表中选择
+	theme := ctx.Request().Query("theme", "")
+	if theme != "" && !Capt.IsThemeAllowed(theme) {
+		return ctx.Response().Json(http.StatusBadRequest, http.Json{
+			"error":  "验证码配色不支持",
+			"themes": Capt.AllowedThemes(),
+		})
+	}
+
+	dots, b64, tb64, key, err := Capt.MakeThemedCaptcha(name, c.identity(ctx), theme)
 	if err != nil {
 		return ctx.Response().Json(http.StatusInternalServerError, http.Json{
-			"errors": err,
+			"errors": err.Error(),
 		})
 	}
 	return ctx.Response().Success().Json(http.Json{
@@ -39,7 +71,7 @@ func (c *CaptchaController) PostCaptcha(ctx http.Context) http.Response {
 		})
 	}
 
-	checked := Capt.VerifyCaptcha(req.Key, req.Dots)
+	checked := Capt.VerifyAdaptiveCaptcha(c.profile(ctx), c.identity(ctx), req.Key, req.Dots)
 	if checked {
 		return ctx.Response().Success().Json(http.Json{
 			"message": "验证成功",
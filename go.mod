@@ -5,6 +5,7 @@ go 1.22.4
 require (
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	github.com/goravel/framework v1.14.4
+	github.com/rivo/uniseg v0.4.7
 	github.com/wenlng/go-captcha v1.2.5
 	golang.org/x/image v0.18.0
 )
@@ -112,7 +113,6 @@ require (
 	github.com/redis/go-redis/v9 v9.5.2 // indirect
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	github.com/rifflock/lfshook v0.0.0-20180920164130-b9218ef580f5 // indirect
-	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/robfig/cron/v3 v3.0.1 // indirect
 	github.com/rotisserie/eris v0.5.4 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
@@ -1,6 +1,85 @@
 package instance
 
-import "github.com/hulutech-web/goravel-captcha/assets"
+import (
+	"bytes"
+	"image"
+
+	"github.com/hulutech-web/goravel-captcha/assets"
+)
+
+/**
+ * @Description: 选项读取的资源，以缓存路径为键，配置应用成功后才写入资源缓存，
+ * 被拒绝的选项不会影响其他验证码正在使用的资源
+ */
+type stagedAssets map[string][]byte
+
+/**
+ * @Description: 暂存资源
+ * @receiver c
+ * @param path	缓存路径
+ * @param content
+ */
+func (c *Config) stageAsset(path string, content []byte) {
+	if c.staged == nil {
+		c.staged = make(stagedAssets)
+	}
+	c.staged[path] = content
+}
+
+/**
+ * @Description: 资源是否暂存或已缓存
+ * @receiver c
+ * @param path
+ * @return bool
+ */
+func (c *Config) hasAsset(path string) bool {
+	file, _ := assets.SplitFontPath(path)
+	if _, ok := c.staged[file]; ok {
+		return true
+	}
+	return hasAssetCache(file)
+}
+
+/**
+ * @Description: 获取字体，暂存的字体每次重新解析，不写入缓存
+ * @receiver c
+ * @param path
+ * @return *assets.Font
+ * @return error
+ */
+func (c *Config) assetFont(path string) (*assets.Font, error) {
+	file, index := assets.SplitFontPath(path)
+	if content, ok := c.staged[file]; ok {
+		return assets.ParseFont(content, index)
+	}
+	return getAssetFont(path)
+}
+
+/**
+ * @Description: 获取图片，暂存的图片每次重新解码，不写入缓存
+ * @receiver c
+ * @param path
+ * @return image.Image
+ * @return error
+ */
+func (c *Config) assetImage(path string) (image.Image, error) {
+	if content, ok := c.staged[path]; ok {
+		img, _, err := image.Decode(bytes.NewReader(content))
+		return img, err
+	}
+	return getAssetImage(path)
+}
+
+/**
+ * @Description: 暂存的资源写入资源缓存，在配置应用成功后调用
+ * @receiver c
+ */
+func (c *Config) publishAssets() {
+	for path, content := range c.staged {
+		setAssetCache(path, content, true)
+	}
+	c.staged = nil
+}
 
 /**
  * @Description: 获取缓存资源
@@ -12,6 +91,26 @@ func getAssetCache(path string) (ret []byte, erro error) {
 	return assets.GetAssetCache(path)
 }
 
+/**
+ * @Description: 获取缓存的已解析字体，字体集合通过 "路径#序号" 指定其中的字体
+ * @param path
+ * @return *assets.Font
+ * @return error
+ */
+func getAssetFont(path string) (*assets.Font, error) {
+	return assets.GetAssetFont(path)
+}
+
+/**
+ * @Description: 获取缓存的已解码图片
+ * @param path
+ * @return image.Image
+ * @return error
+ */
+func getAssetImage(path string) (image.Image, error) {
+	return assets.GetAssetImage(path)
+}
+
 /**
  * @Description: 资源是否缓存
  * @param path
@@ -28,7 +127,10 @@ func hasAssetCache(path string) bool {
  * @return bool
  */
 func clearAssetCache(paths []string) bool {
-	return assets.ClearAssetCache(paths)
+	ok := assets.ClearAssetCache(paths)
+	// 字形覆盖按缓存版本计算，替换后旧版本的结果不会再命中，这里只是释放
+	resetGlyphCoverage()
+	return ok
 }
 
 /**
@@ -37,7 +139,12 @@ func clearAssetCache(paths []string) bool {
  * @return error
  */
 func setAssetCache(path string, content []byte, force bool) bool {
-	return assets.SetAssetCache(path, content, force)
+	ok := assets.SetAssetCache(path, content, force)
+	if force {
+		// 字体可能被替换，旧版本的字形覆盖不会再命中，这里只是释放
+		resetGlyphCoverage()
+	}
+	return ok
 }
 
 // =============================================
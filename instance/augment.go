@@ -0,0 +1,111 @@
+package instance
+
+import (
+	"image"
+	"image/color"
+	"math"
+	mRand "math/rand"
+
+	"golang.org/x/image/draw"
+)
+
+/**
+ * @Description: 对背景裁剪区域做随机增强，同一张背景图每次生成的像素都不同，无法通过减去已知背景分离出文本
+ * @param img		背景图
+ * @param cut		裁剪位置
+ * @param size		主图尺寸
+ * @param intensity	增强强度 0-1
+ * @return *image.RGBA	裁剪并增强后的背景，裁剪位置为 (0, 0)
+ */
+func augmentBackground(img image.Image, cut image.Point, size Size, intensity float64) *image.RGBA {
+	r := mRand.New(mRand.NewSource(int64(RandInt(0, math.MaxInt32))))
+	w, h := size.Width, size.Height
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	// 水平翻转
+	flip := r.Intn(2) == 1
+	// 色相偏移（色相环的比例）、饱和度倍数、亮度偏移
+	hueShift := (r.Float64()*2 - 1) * 0.08 * intensity
+	satScale := 1 + (r.Float64()*2-1)*0.3*intensity
+	lightShift := (r.Float64()*2 - 1) * 0.12 * intensity
+
+	// 标准库对 *image.YCbCr 及 *image.RGBA 有快速路径，超出背景图的部分保持为零，下面补为不透明的黑色
+	b := img.Bounds()
+	draw.Draw(dst, dst.Rect, img, b.Min.Add(cut), draw.Src)
+	for y := 0; y < h; y++ {
+		row := dst.Pix[y*dst.Stride : y*dst.Stride+w*4]
+		if flip {
+			var px [4]uint8
+			for i, j := 0, (w-1)*4; i < j; i, j = i+4, j-4 {
+				copy(px[:], row[i:i+4])
+				copy(row[i:i+4], row[j:j+4])
+				copy(row[j:j+4], px[:])
+			}
+		}
+		for i := 0; i < len(row); i += 4 {
+			hu, s, l := rgbToHsl(color.RGBA{R: row[i], G: row[i+1], B: row[i+2], A: 0xff})
+			hu = math.Mod(hu+hueShift+1, 1)
+			s = math.Min(math.Max(s*satScale, 0), 1)
+			l = math.Min(math.Max(l+lightShift, 0), 1)
+			c := hslToRgb(hu, s, l)
+			row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, 0xff
+		}
+	}
+
+	// 轻微模糊或锐化，各占三分之一的概率
+	switch r.Intn(3) {
+	case 0:
+		convolve3(dst, 0.5*intensity)
+	case 1:
+		convolve3(dst, -0.8*intensity)
+	}
+
+	// 逐像素噪声
+	amp := 12 * intensity
+	for i := 0; i < len(dst.Pix); i += 4 {
+		for c := 0; c < 3; c++ {
+			v := float64(dst.Pix[i+c]) + r.NormFloat64()*amp
+			dst.Pix[i+c] = uint8(math.Min(math.Max(math.Round(v), 0), 255))
+		}
+	}
+	return dst
+}
+
+/**
+ * @Description: 3x3 均值模糊与原图按比例混合，比例为负时为反锐化掩模
+ * @param m
+ * @param amount	大于0时模糊，小于0时锐化
+ */
+func convolve3(m *image.RGBA, amount float64) {
+	if amount == 0 {
+		return
+	}
+
+	src := append([]uint8(nil), m.Pix...)
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [3]float64
+			n := 0.0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					j := ny*m.Stride + nx*4
+					sum[0] += float64(src[j])
+					sum[1] += float64(src[j+1])
+					sum[2] += float64(src[j+2])
+					n++
+				}
+			}
+
+			i := y*m.Stride + x*4
+			for c := 0; c < 3; c++ {
+				v := float64(src[i+c]) + (sum[c]/n-float64(src[i+c]))*amount
+				m.Pix[i+c] = uint8(math.Min(math.Max(math.Round(v), 0), 255))
+			}
+		}
+	}
+}
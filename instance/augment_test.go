@@ -0,0 +1,103 @@
+package instance
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+// 复制图片的像素，用于比较缓存的图片是否被修改
+func imagePixels(img image.Image) []byte {
+	m := image.NewRGBA(img.Bounds())
+	draw.Draw(m, m.Bounds(), img, img.Bounds().Min, draw.Src)
+	return m.Pix
+}
+
+func TestAugmentBackgroundDoesNotMutateCache(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 160, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 160; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y * 2), B: uint8(x + y), A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	path := "test/augment.png"
+	setAssetCache(path, buf.Bytes(), true)
+	t.Cleanup(func() { clearAssetCache([]string{path}) })
+
+	cached, err := getAssetImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := imagePixels(cached)
+
+	cut, size := image.Point{X: 20, Y: 10}, Size{Width: 100, Height: 80}
+	a := augmentBackground(cached, cut, size, 1)
+	b := augmentBackground(cached, cut, size, 1)
+	if bytes.Equal(a.Pix, b.Pix) {
+		t.Fatal("augmenting the same background twice produced identical pixels")
+	}
+
+	again, err := getAssetImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != cached {
+		t.Fatal("getAssetImage() returned a different image, want the shared cached one")
+	}
+	if !bytes.Equal(imagePixels(cached), before) {
+		t.Fatal("augmentBackground() mutated the cached background")
+	}
+}
+
+func TestAugmentBackgroundCrop(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 6), B: 0x80, A: 0xff})
+		}
+	}
+	cut, size := image.Point{X: 10, Y: 5}, Size{Width: 40, Height: 40}
+
+	// 强度为 0 时只裁剪或水平翻转，超出背景图的部分为黑色
+	for i := 0; i < 8; i++ {
+		dst := augmentBackground(src, cut, size, 0)
+		flip := dst.RGBAAt(0, 0) != src.RGBAAt(cut.X, cut.Y)
+		for y := 0; y < size.Height; y++ {
+			for x := 0; x < size.Width; x++ {
+				sx := cut.X + x
+				if flip {
+					sx = cut.X + size.Width - 1 - x
+				}
+				want := color.RGBA{A: 0xff}
+				if sy := cut.Y + y; sy < 40 {
+					want = src.RGBAAt(sx, sy)
+				}
+				if got := dst.RGBAAt(x, y); got != want {
+					t.Fatalf("flip=%v: pixel (%d,%d) = %v, want %v", flip, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkAugmentBackground(b *testing.B) {
+	// 背景图按 JPEG 解码为 *image.YCbCr
+	img, err := getAssetImage(DefaultConfig().RangBackground[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+	size := Size{Width: 300, Height: 240}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		augmentBackground(img, image.Point{}, size, 1)
+	}
+}
@@ -1,7 +1,9 @@
 package instance
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/wenlng/go-captcha/captcha"
 	"golang.org/x/image/font"
@@ -10,13 +12,15 @@ import (
 	"io"
 	"io/ioutil"
 	"math"
-	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"net/url"
+
+	"github.com/hulutech-web/goravel-captcha/assets"
+	"github.com/hulutech-web/goravel-captcha/resources"
 )
 
 // CharDot is a type
@@ -43,6 +47,10 @@ type CharDot struct {
 	Color string
 	// 颜色2
 	Color2 string
+	// 绘制使用的字体，尺寸按该字体度量
+	Font string `json:"-"`
+	// 图标模式下绘制的图标，Text 为图标名称
+	Icon string `json:"-"`
 }
 
 // Captcha is a type
@@ -50,38 +58,52 @@ type CharDot struct {
  * @Description: 点选验证码
  */
 type Captcha struct {
-	// 字符集合，用于随机字符串
-	chars *[]string
 	// 难度码配置
 	config *Config
 	// 验证画图
 	captchaDraw *Draw
+	// 预渲染池
+	pool *Pool
+	// 保护 config、pool 指针的替换，config 本身只读，修改时复制
+	mu sync.RWMutex
 }
 
 var _instance *Captcha
 var _once sync.Once
+var _initOnce sync.Once
 
 // NewCaptcha is a function
 /**
- * @Description: 创建点选验证码
+ * @Description: 创建点选验证码，在默认配置上依次应用选项并校验，实例之间互不影响
+ * @param opts
  * @return *Captcha
+ * @return error
  */
-func NewCaptcha() *Captcha {
+func NewCaptcha(opts ...Option) (*Captcha, error) {
+	cfg, err := NewConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.validation = &validation{}
 	return &Captcha{
-		chars:       GetCaptchaDefaultChars(),
-		config:      GetCaptchaDefaultConfig(),
+		config:      &cfg,
 		captchaDraw: &Draw{},
-	}
+	}, nil
 }
 
 // GetCaptcha is a function
 /**
- * @Description: 获取点选验证码,是安全的
+ * @Description: 获取点选验证码,是安全的，默认配置不合法时 panic
  * @return *Captcha
  */
 func GetCaptcha() *Captcha {
 	_once.Do(func() {
-		_instance = NewCaptcha()
+		// 默认配置由程序决定，不合法时与 InitConfig 一样直接 panic
+		var err error
+		if _instance, err = NewCaptcha(); err != nil {
+			panic(err)
+		}
 	})
 
 	return _instance
@@ -89,32 +111,74 @@ func GetCaptcha() *Captcha {
 
 // SetRangChars is a function
 /**
- * @Description: 设置随机字符串，每个单词不能超出2个字符，超出会影响位置的验证
+ * @Description: 设置随机字符串，每个字符的显示宽度不能超出2，即一个中日韩文字或两个拉丁字母，超出会影响位置的验证
  * @receiver cc
  * @param chars
- * @return error
+ * @return error	所有不合法的字符
  */
 func (cc *Captcha) SetRangChars(chars []string) error {
-	var err error
-	if len(chars) > 0 {
-		for _, char := range chars {
-			if IsChineseChar(char) {
-				if LenChineseChar(char) > 1 {
-					err = fmt.Errorf("Captcha SetRangChars Error: The chinese char [%s] must be equal to 1", char)
-					break
-				}
-			} else if LenChineseChar(char) > 2 {
-				err = fmt.Errorf("Captcha SetRangChars Error: The char [%s] must be less than or equal to 2", char)
-				break
-			}
-		}
+	return cc.applyGlyphs(WithRangChars(chars))
+}
+
+/**
+ * @Description: 复制当前配置后应用选项并替换，选项出错时保留原配置，正在生成的验证码仍使用旧的配置快照
+ * @receiver cc
+ * @param opt
+ * @return error
+ */
+func (cc *Captcha) apply(opt Option) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	c := cc.config.Clone()
+	if err := opt(&c); err != nil {
+		return err
 	}
+	c.publishAssets()
+	c.validation = &validation{}
+	cc.config = &c
+	return nil
+}
 
-	if err != nil {
+/**
+ * @Description: 获取当前配置快照，调用方只读
+ * @receiver cc
+ * @return *Config
+ */
+func (cc *Captcha) snapshot() *Config {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+
+	return cc.config
+}
+
+// Config is a function
+/**
+ * @Description: 获取当前配置的副本
+ * @receiver cc
+ * @return Config
+ */
+func (cc *Captcha) Config() Config {
+	return cc.snapshot().Clone()
+}
+
+// SetConfig is a function
+/**
+ * @Description: 校验并整体替换配置
+ * @receiver cc
+ * @param cfg
+ * @return error
+ */
+func (cc *Captcha) SetConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
 		return err
 	}
 
-	cc.chars = &chars
+	c := cfg.Clone()
+	c.validation = &validation{}
+	cc.mu.Lock()
+	cc.config = &c
+	cc.mu.Unlock()
 	return nil
 }
 
@@ -128,25 +192,10 @@ func (cc *Captcha) SetRangChars(chars []string) error {
  * @receiver cc
  * @param images
  * @param args	true|false 是否强制刷新缓存
+ * @return error	所有无法读取的文件
  */
-func (cc *Captcha) SetBackground(images []string, args ...bool) {
-	for _, path := range images {
-		if has, err := PathExists(path); !has || err != nil {
-			panic(fmt.Errorf("CaptchaConfig Error: The [%s] file does not exist", path))
-		}
-
-		hasCache := hasAssetCache(path)
-		if !hasCache || (hasCache && len(args) > 0 && args[0]) {
-			bytes, err := ioutil.ReadFile(path)
-			if err != nil {
-				panic(err)
-			}
-
-			setAssetCache(path, bytes, len(args) > 0 && args[0])
-		}
-	}
-
-	cc.config.rangBackground = images
+func (cc *Captcha) SetBackground(images []string, args ...bool) error {
+	return cc.apply(WithBackground(images, args...))
 }
 
 // SetFont is a function
@@ -155,24 +204,10 @@ func (cc *Captcha) SetBackground(images []string, args ...bool) {
  * @receiver cc
  * @param fonts
  * @param args	true|false 是否强制刷新缓存
+ * @return error	所有无法读取的文件
  */
-func (cc *Captcha) SetFont(fonts []string, args ...bool) {
-	for _, path := range fonts {
-		if has, err := PathExists(path); !has || err != nil {
-			panic(fmt.Errorf("CaptchaConfig Error: The [%s] file does not exist", path))
-		}
-		hasCache := hasAssetCache(path)
-		if !hasCache || (hasCache && len(args) > 0 && args[0]) {
-			bytes, err := ioutil.ReadFile(path)
-			if err != nil {
-				panic(err)
-			}
-
-			setAssetCache(path, bytes, len(args) > 0 && args[0])
-		}
-	}
-
-	cc.config.rangFont = fonts
+func (cc *Captcha) SetFont(fonts []string, args ...bool) error {
+	return cc.applyGlyphs(WithFont(fonts, args...))
 }
 
 // SetImageSize is a function
@@ -180,9 +215,10 @@ func (cc *Captcha) SetFont(fonts []string, args ...bool) {
  * @Description: 设置验证码尺寸
  * @receiver cc
  * @param size
+ * @return error
  */
-func (cc *Captcha) SetImageSize(size Size) {
-	cc.config.imageSize = size
+func (cc *Captcha) SetImageSize(size Size) error {
+	return cc.apply(WithImageSize(size))
 }
 
 // SetImageQuality is a function
@@ -190,11 +226,10 @@ func (cc *Captcha) SetImageSize(size Size) {
  * @Description: 设置验证码清晰度
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetImageQuality(val int) {
-	if val == QualityCompressNone || (val <= QualityCompressLevel1 && val >= QualityCompressLevel5) {
-		cc.config.imageQuality = val
-	}
+func (cc *Captcha) SetImageQuality(val int) error {
+	return cc.apply(WithImageQuality(val))
 }
 
 // SetThumbSize is a function
@@ -202,9 +237,10 @@ func (cc *Captcha) SetImageQuality(val int) {
  * @Description: 设置缩略图尺寸
  * @receiver cc
  * @param size
+ * @return error
  */
-func (cc *Captcha) SetThumbSize(size Size) {
-	cc.config.thumbnailSize = size
+func (cc *Captcha) SetThumbSize(size Size) error {
+	return cc.apply(WithThumbSize(size))
 }
 
 // SetRangFontSize is a function
@@ -212,9 +248,10 @@ func (cc *Captcha) SetThumbSize(size Size) {
  * @Description: 设置随机字体大小
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetRangFontSize(val RangeVal) {
-	cc.config.rangFontSize = val
+func (cc *Captcha) SetRangFontSize(val RangeVal) error {
+	return cc.apply(WithRangFontSize(val))
 }
 
 // SetTextRangLen is a function
@@ -222,9 +259,10 @@ func (cc *Captcha) SetRangFontSize(val RangeVal) {
  * @Description: 设置字符随机长度范围
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetTextRangLen(val RangeVal) {
-	cc.config.rangTextLen = val
+func (cc *Captcha) SetTextRangLen(val RangeVal) error {
+	return cc.apply(WithTextRangLen(val))
 }
 
 // SetTextRangFontColors is a function
@@ -232,9 +270,10 @@ func (cc *Captcha) SetTextRangLen(val RangeVal) {
  * @Description: 设置文本随机颜色
  * @receiver cc
  * @param colors
+ * @return error
  */
-func (cc *Captcha) SetTextRangFontColors(colors []string) {
-	cc.config.rangFontColors = colors
+func (cc *Captcha) SetTextRangFontColors(colors []string) error {
+	return cc.apply(WithTextRangFontColors(colors))
 }
 
 // SetThumbTextRangFontColors is a function
@@ -242,9 +281,10 @@ func (cc *Captcha) SetTextRangFontColors(colors []string) {
  * @Description: 设置缩略图文本随机颜色
  * @receiver cc
  * @param colors
+ * @return error
  */
-func (cc *Captcha) SetThumbTextRangFontColors(colors []string) {
-	cc.config.rangThumbFontColors = colors
+func (cc *Captcha) SetThumbTextRangFontColors(colors []string) error {
+	return cc.apply(WithThumbTextRangFontColors(colors))
 }
 
 // SetFontDPI is a function
@@ -252,19 +292,21 @@ func (cc *Captcha) SetThumbTextRangFontColors(colors []string) {
  * @Description: 设置分辨率，72为标准
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetFontDPI(val int) {
-	cc.config.fontDPI = val
+func (cc *Captcha) SetFontDPI(val int) error {
+	return cc.apply(WithFontDPI(val))
 }
 
-// SetFontDPI is a function
+// SetFontHinting is a function
 /**
- * @Description: 设置分辨率，72为标准
+ * @Description: 设置字体Hinting
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetFontHinting(val font.Hinting) {
-	cc.config.fontHinting = val
+func (cc *Captcha) SetFontHinting(val font.Hinting) error {
+	return cc.apply(WithFontHinting(val))
 }
 
 // SetImageFontAlpha is a function
@@ -272,9 +314,10 @@ func (cc *Captcha) SetFontHinting(val font.Hinting) {
  * @Description: 设置验证码文本透明度
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetImageFontAlpha(val float64) {
-	cc.config.imageFontAlpha = val
+func (cc *Captcha) SetImageFontAlpha(val float64) error {
+	return cc.apply(WithImageFontAlpha(val))
 }
 
 // SetTextShadow is a function
@@ -282,9 +325,10 @@ func (cc *Captcha) SetImageFontAlpha(val float64) {
  * @Description: 设置验证码文本阴影显示
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetTextShadow(val bool) {
-	cc.config.showTextShadow = val
+func (cc *Captcha) SetTextShadow(val bool) error {
+	return cc.apply(WithTextShadow(val))
 }
 
 // SetTextShadowColor is a function
@@ -292,9 +336,10 @@ func (cc *Captcha) SetTextShadow(val bool) {
  * @Description: 设置验证码文本阴影颜色
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetTextShadowColor(val string) {
-	cc.config.textShadowColor = val
+func (cc *Captcha) SetTextShadowColor(val string) error {
+	return cc.apply(WithTextShadowColor(val))
 }
 
 // SetTextShadowPoint is a function
@@ -302,9 +347,10 @@ func (cc *Captcha) SetTextShadowColor(val string) {
  * @Description: 设置验证码文本阴影位置
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetTextShadowPoint(val Point) {
-	cc.config.textShadowPoint = val
+func (cc *Captcha) SetTextShadowPoint(val Point) error {
+	return cc.apply(WithTextShadowPoint(val))
 }
 
 // SetImageFontDistort is a function
@@ -312,11 +358,10 @@ func (cc *Captcha) SetTextShadowPoint(val Point) {
  * @Description: 设置验证码文本扭曲程度
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetImageFontDistort(val int) {
-	if val >= DistortNone || val <= DistortLevel5 {
-		cc.config.imageFontDistort = val
-	}
+func (cc *Captcha) SetImageFontDistort(val int) error {
+	return cc.apply(WithImageFontDistort(val))
 }
 
 // SetTextRangAnglePos is a function
@@ -324,24 +369,21 @@ func (cc *Captcha) SetImageFontDistort(val int) {
  * @Description: 设置文本角度位置
  * @receiver cc
  * @param pos
+ * @return error
  */
-func (cc *Captcha) SetTextRangAnglePos(pos []RangeVal) {
-	cc.config.rangTexAnglePos = pos
+func (cc *Captcha) SetTextRangAnglePos(pos []RangeVal) error {
+	return cc.apply(WithTextRangAnglePos(pos))
 }
 
 // SetRangCheckTextLen is a function
 /**
- * @Description: 设置随机验证文本长度
+ * @Description: 设置随机验证文本长度，与随机字符串长度的约束由 Validate 检测
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetRangCheckTextLen(val RangeVal) {
-	// 检测验证文本范围最大值是否小于随机字符串的最小范围
-	if cc.config.rangCheckTextLen.Max > cc.config.rangTextLen.Min {
-		panic(fmt.Errorf("CaptchaConfig Error: RangCheckTextLen.max must be less than or equal to RangTextLen.min"))
-	}
-
-	cc.config.rangCheckTextLen = val
+func (cc *Captcha) SetRangCheckTextLen(val RangeVal) error {
+	return cc.apply(WithRangCheckTextLen(val))
 }
 
 // SetRangCheckFontSize is a function
@@ -349,9 +391,10 @@ func (cc *Captcha) SetRangCheckTextLen(val RangeVal) {
  * @Description: 设置随机验证文本大小
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetRangCheckFontSize(val RangeVal) {
-	cc.config.rangCheckFontSize = val
+func (cc *Captcha) SetRangCheckFontSize(val RangeVal) error {
+	return cc.apply(WithRangCheckFontSize(val))
 }
 
 // SetThumbBgColors is a function
@@ -359,35 +402,22 @@ func (cc *Captcha) SetRangCheckFontSize(val RangeVal) {
  * @Description: 设置缩略图随机背景颜色
  * @receiver cc
  * @param colors
+ * @return error
  */
-func (cc *Captcha) SetThumbBgColors(colors []string) {
-	cc.config.rangThumbBgColors = colors
+func (cc *Captcha) SetThumbBgColors(colors []string) error {
+	return cc.apply(WithThumbBgColors(colors))
 }
 
 // SetThumbBackground is a function
 /**
- * @Description: 设置随机背景图片
+ * @Description: 设置缩略图随机背景图片
  * @receiver cc
  * @param images
  * @param args	true|false 是否强制刷新缓存
+ * @return error	所有无法读取的文件
  */
-func (cc *Captcha) SetThumbBackground(images []string, args ...bool) {
-	for _, path := range images {
-		if has, err := PathExists(path); !has || err != nil {
-			panic(fmt.Errorf("CaptchaConfig Error: The [%s] file does not exist", path))
-		}
-		hasCache := hasAssetCache(path)
-		if !hasCache || (hasCache && len(args) > 0 && args[0]) {
-			bytes, err := ioutil.ReadFile(path)
-			if err != nil {
-				panic(err)
-			}
-
-			setAssetCache(path, bytes, len(args) > 0 && args[0])
-		}
-	}
-
-	cc.config.rangThumbBackground = images
+func (cc *Captcha) SetThumbBackground(images []string, args ...bool) error {
+	return cc.apply(WithThumbBackground(images, args...))
 }
 
 // SetThumbBgDistort is a function
@@ -395,11 +425,10 @@ func (cc *Captcha) SetThumbBackground(images []string, args ...bool) {
  * @Description: 设置缩略图扭曲程度
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetThumbBgDistort(val int) {
-	if val >= DistortNone || val <= DistortLevel5 {
-		cc.config.thumbBgDistort = val
-	}
+func (cc *Captcha) SetThumbBgDistort(val int) error {
+	return cc.apply(WithThumbBgDistort(val))
 }
 
 // SetThumbFontDistort is a function
@@ -407,11 +436,10 @@ func (cc *Captcha) SetThumbBgDistort(val int) {
  * @Description: 设置缩略图字体扭曲程度
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetThumbFontDistort(val int) {
-	if val >= DistortNone || val <= DistortLevel5 {
-		cc.config.thumbFontDistort = val
-	}
+func (cc *Captcha) SetThumbFontDistort(val int) error {
+	return cc.apply(WithThumbFontDistort(val))
 }
 
 // SetThumbBgCirclesNum is a function
@@ -419,9 +447,10 @@ func (cc *Captcha) SetThumbFontDistort(val int) {
  * @Description: 设置缩略图小圆点数量
  * @receiver cc
  * @param val
+ * @return error
  */
-func (cc *Captcha) SetThumbBgCirclesNum(val int) {
-	cc.config.thumbBgCirclesNum = val
+func (cc *Captcha) SetThumbBgCirclesNum(val int) error {
+	return cc.apply(WithThumbBgCirclesNum(val))
 }
 
 // SetThumbBgSlimLineNum is a function
@@ -429,9 +458,165 @@ func (cc *Captcha) SetThumbBgCirclesNum(val int) {
  * @Description: 缩略图线条数量
  * @receiver cc
  * @param val
+ * @return error
+ */
+func (cc *Captcha) SetThumbBgSlimLineNum(val int) error {
+	return cc.apply(WithThumbBgSlimLineNum(val))
+}
+
+// SetTextMinGap is a function
+/**
+ * @Description: 设置主图文本之间的最小间距
+ * @receiver cc
+ * @param val
+ * @return error
  */
-func (cc *Captcha) SetThumbBgSlimLineNum(val int) {
-	cc.config.thumbBgSlimLineNum = val
+func (cc *Captcha) SetTextMinGap(val int) error {
+	return cc.apply(WithTextMinGap(val))
+}
+
+// SetTextEdgeMargin is a function
+/**
+ * @Description: 设置主图文本距离图片边缘的最小距离
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetTextEdgeMargin(val int) error {
+	return cc.apply(WithTextEdgeMargin(val))
+}
+
+// SetTextPlacement is a function
+/**
+ * @Description: 设置主图文本排布方式，PlacementRandom 或 PlacementSaliency
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetTextPlacement(val int) error {
+	return cc.apply(WithTextPlacement(val))
+}
+
+// SetTextContrast is a function
+/**
+ * @Description: 设置主图文本与背景的最低对比度，0 为不调整文本颜色
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetTextContrast(val float64) error {
+	return cc.apply(WithTextContrast(val))
+}
+
+// SetThumbBgFillColor is a function
+/**
+ * @Description: 设置缩略图底色，为空时透明
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetThumbBgFillColor(val string) error {
+	return cc.apply(WithThumbBgFillColor(val))
+}
+
+// SetImageWaveLineNum is a function
+/**
+ * @Description: 设置主图穿过文本区域的波浪线数量
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetImageWaveLineNum(val int) error {
+	return cc.apply(WithImageWaveLineNum(val))
+}
+
+// SetImageArcNum is a function
+/**
+ * @Description: 设置主图随机弧线数量
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetImageArcNum(val int) error {
+	return cc.apply(WithImageArcNum(val))
+}
+
+// SetImageNoiseDotNum is a function
+/**
+ * @Description: 设置主图噪点数量
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetImageNoiseDotNum(val int) error {
+	return cc.apply(WithImageNoiseDotNum(val))
+}
+
+// SetImageDecoyStrokeNum is a function
+/**
+ * @Description: 设置主图与文本笔画相似的干扰笔画数量
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetImageDecoyStrokeNum(val int) error {
+	return cc.apply(WithImageDecoyStrokeNum(val))
+}
+
+// SetImageInterferenceAlpha is a function
+/**
+ * @Description: 设置主图干扰透明度
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetImageInterferenceAlpha(val float64) error {
+	return cc.apply(WithImageInterferenceAlpha(val))
+}
+
+// SetBackgroundAugment is a function
+/**
+ * @Description: 设置背景增强强度，0 为不处理
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetBackgroundAugment(val float64) error {
+	return cc.apply(WithBackgroundAugment(val))
+}
+
+/**
+ * @Description: 读取文件并暂存，配置应用成功后写入资源缓存
+ * @receiver c
+ * @param paths
+ * @param force	是否强制刷新缓存
+ * @return error	所有不存在或无法读取的文件
+ */
+func (c *Config) loadAssetPaths(paths []string, force bool) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("CaptchaConfig Error: At least one file is required")
+	}
+
+	var errs []error
+	for _, path := range paths {
+		// 字体集合中的字体以 "路径#序号" 表示，按文件读取
+		path, _ = assets.SplitFontPath(path)
+		if has, err := PathExists(path); !has || err != nil {
+			errs = append(errs, fmt.Errorf("CaptchaConfig Error: The [%s] file does not exist", path))
+			continue
+		}
+
+		if c.hasAsset(path) && !force {
+			continue
+		}
+		bytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CaptchaConfig Error: The [%s] file can not be read: %w", path, err))
+			continue
+		}
+		c.stageAsset(path, bytes)
+	}
+	return errors.Join(errs...)
 }
 
 // =============================================
@@ -448,37 +633,100 @@ func (cc *Captcha) ClearAssetCacheWithPaths(paths []string) bool {
 
 // =============================================
 
+// Generate is a function
 /**
- * @Description: 检测配置是否完整和合法，字体和图片背景必须设置
- * @receiver cc
+ * @Description: 			根据设置的尺寸生成验证码图片
+ * @return CaptchaCharDot	位置信息
+ * @return string			主图Base64
+ * @return string			验证码KEY
+ * @return string			缩略图Base64
  * @return error
  */
-func (cc *Captcha) checkConfig() error {
-	// 验证颜色总和是否超出255个
-	if len(cc.config.rangFontColors) >= 255 {
-		return fmt.Errorf("CaptchaConfig Error: len(rangFontColors) must be less than or equal to 255")
+func (cc *Captcha) Generate() (map[int]CharDot, string, string, string, error) {
+	cc.mu.RLock()
+	pool := cc.pool
+	cc.mu.RUnlock()
+	if pool != nil {
+		if ch, ok := pool.Get(); ok {
+			return ch.Dots, ch.Image, ch.ThumbImage, ch.Key, nil
+		}
 	}
 
-	// 验证颜色总和是否超出255个
-	if len(cc.config.rangThumbFontColors)+len(cc.config.rangThumbBgColors) >= 255 {
-		return fmt.Errorf("CaptchaConfig Error: len(rangThumbBgColors + RangThumbBgColors) must be less than or equal to 255")
+	return cc.render()
+}
+
+// StartPool is a function
+/**
+ * @Description: 启用预渲染池，Generate 优先从池中取出验证码，池为空时同步生成
+ * @receiver cc
+ * @param config
+ * @return *Pool
+ */
+func (cc *Captcha) StartPool(config PoolConfig) *Pool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.pool != nil {
+		return cc.pool
 	}
 
-	return nil
+	cc.pool = NewPool(cc, config)
+	cc.pool.Start()
+	return cc.pool
 }
 
-// Generate is a function
+// ClosePool is a function
 /**
- * @Description: 			根据设置的尺寸生成验证码图片
+ * @Description: 关闭预渲染池，等待后台渲染协程退出
+ * @receiver cc
+ * @param ctx
+ * @return error
+ */
+func (cc *Captcha) ClosePool(ctx context.Context) error {
+	cc.mu.Lock()
+	pool := cc.pool
+	cc.pool = nil
+	cc.mu.Unlock()
+
+	if pool == nil {
+		return nil
+	}
+	return pool.Shutdown(ctx)
+}
+
+/**
+ * @Description: 按配置尺寸同步生成验证码
+ * @receiver cc
  * @return CaptchaCharDot	位置信息
  * @return string			主图Base64
- * @return string			验证码KEY
  * @return string			缩略图Base64
+ * @return string			验证码KEY
  * @return error
  */
-func (cc *Captcha) Generate() (map[int]CharDot, string, string, string, error) {
-	dots, ib64, tb64, key, err := cc.GenerateWithSize(cc.config.imageSize, cc.config.thumbnailSize)
-	return dots, ib64, tb64, key, err
+func (cc *Captcha) render() (map[int]CharDot, string, string, string, error) {
+	cfg := cc.snapshot()
+	return cc.generate(cfg, cfg.ImageSize, cfg.ThumbnailSize)
+}
+
+/**
+ * @Description: 按配置尺寸渲染一个验证码，供预渲染池使用
+ * @receiver cc
+ * @return *Challenge
+ * @return error
+ */
+func (cc *Captcha) renderChallenge() (*Challenge, error) {
+	dots, ib64, tb64, key, err := cc.render()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Challenge{
+		Dots:       dots,
+		Image:      ib64,
+		ThumbImage: tb64,
+		Key:        key,
+		CreatedAt:  time.Now(),
+	}, nil
 }
 
 // GenerateWithSize is a function
@@ -493,29 +741,112 @@ func (cc *Captcha) Generate() (map[int]CharDot, string, string, string, error) {
  * @return error
  */
 func (cc *Captcha) GenerateWithSize(imageSize Size, thumbnailSize Size) (map[int]CharDot, string, string, string, error) {
-	err := cc.checkConfig()
-	length := RandInt(cc.config.rangTextLen.Min, cc.config.rangTextLen.Max)
-	chars := cc.genRandChar(length)
+	cfg := cc.snapshot()
+	return cc.generate(cfg, imageSize, thumbnailSize)
+}
+
+// GenerateWithConfig is a function
+/**
+ * @Description: 			使用临时配置生成验证码图片，不修改实例配置，也不使用预渲染池
+ * @param cfg				临时配置，例如按失败记录提高难度后的配置
+ * @return CaptchaCharDot	位置信息
+ * @return string			主图Base64
+ * @return string			缩略图Base64
+ * @return string			验证码KEY
+ * @return error
+ */
+func (cc *Captcha) GenerateWithConfig(cfg Config) (map[int]CharDot, string, string, string, error) {
+	c := cfg.Clone()
+	return cc.generate(&c, c.ImageSize, c.ThumbnailSize)
+}
+
+/**
+ * @Description: 			使用配置快照生成验证码图片，生成过程不修改共享状态
+ * @param cfg				配置快照
+ * @param imageSize			主图尺寸
+ * @param thumbnailSize		缩略图尺寸
+ * @return CaptchaCharDot	位置信息
+ * @return string			主图Base64
+ * @return string			验证码KEY
+ * @return string			缩略图Base64
+ * @return error
+ */
+func (cc *Captcha) generate(cfg *Config, imageSize Size, thumbnailSize Size) (map[int]CharDot, string, string, string, error) {
+	// 生成字符前检测配置，避免生成无法校验的验证码，实例快照只校验一次
+	if err := cfg.validateOnce(); err != nil {
+		return nil, "", "", "", err
+	}
+
+	// 没有任何字体包含的字符不参与生成
+	cov, err := glyphCoverageOf(cfg, glyphChars(cfg), cfg.RangFont)
+	if err != nil {
+		return nil, "", "", "", err
+	}
+
+	length := RandInt(cfg.RangTextLen.Min, cfg.RangTextLen.Max)
+	var chars string
+	// 词语模式下验证文本为词语，主图其余为干扰字符
+	var phrase []string
+	if cfg.TextMode == TextModePhrase {
+		phrase = randPhrase(cfg, cov)
+		if phrase == nil {
+			return nil, "", "", "", fmt.Errorf("genCaptchaImage Error: No phrase generation")
+		}
+		chars = cc.genPhraseChars(cov.usable(cfg.RangChars), phrase, max(length, len(phrase)))
+	} else if cfg.TextMode == TextModeIcon {
+		chars = cc.genRandChar(iconNames(cfg), length)
+	} else {
+		chars = cc.genRandChar(cov.usable(cfg.RangChars), length)
+	}
 	if chars == "" {
 		return nil, "", "", "", fmt.Errorf("genCaptchaImage Error: No character generation")
 	}
+	// 形近字不会被放入同一个验证码，字符不足时报错而不是放宽限制
+	if n := strings.Count(chars, ":") + 1; n < length {
+		return nil, "", "", "", fmt.Errorf("genCaptchaImage Error: Only %d dissimilar chars for %d chars", n, length)
+	}
 
 	var allDots, thumbDots, checkDots map[int]CharDot
 	var imageBase64, tImageBase64 string
 	var checkChars string
 
-	allDots = cc.genDots(imageSize, cc.config.rangFontSize, chars, 10)
-	// checkChars = "A:B:C"
-	checkDots, checkChars = cc.rangeCheckDots(allDots)
-	thumbDots = cc.genDots(thumbnailSize, cc.config.rangCheckFontSize, checkChars, 0)
+	allDots = cc.genDots(cfg, cov, imageSize, cfg.RangFontSize, chars, 10)
+	// 先确定背景及裁剪位置，排布文本时可以参考背景
+	var background string
+	if len(cfg.RangBackground) > 0 {
+		background = cc.genRandWithString(cfg.RangBackground)
+	}
+	bgImg := cc.loadBackground(background, imageSize)
+	var cut image.Point
+	cut.X, cut.Y = cc.captchaDraw.rangCutImage(imageSize.Width, imageSize.Height, bgImg)
+	if cfg.BackgroundAugment > 0 {
+		bgImg = augmentBackground(bgImg, cut, imageSize, cfg.BackgroundAugment)
+		cut = image.Point{}
+	}
+	var sal *saliencyMap
+	if cfg.TextPlacement == PlacementSaliency {
+		sal = newSaliencyMap(bgImg, cut, imageSize, cfg.RangFontColors)
+	}
+	// 主图文本互不重叠，校验区域为旋转后的占位区域
+	answerDots, err := cc.placeDots(cfg, imageSize, allDots, sal)
 	if err != nil {
 		return nil, "", "", "", err
 	}
-	imageBase64, err = cc.genCaptchaImage(imageSize, allDots)
+	if cfg.TextContrast > 0 {
+		cc.applyContrast(cfg, bgImg, cut, allDots, answerDots)
+	}
+	// checkChars = "A:B:C"
+	if phrase != nil {
+		checkDots, checkChars = cc.phraseCheckDots(answerDots, len(phrase))
+	} else {
+		checkDots, checkChars = cc.rangeCheckDots(cfg, answerDots)
+	}
+	thumbDots = cc.genDots(cfg, cov, thumbnailSize, cfg.RangCheckFontSize, checkChars, 0)
+	imageBase64, err = cc.genCaptchaImage(cfg, imageSize, allDots, bgImg, cut)
 	if err != nil {
 		return nil, "", "", "", err
 	}
-	tImageBase64, err = cc.genCaptchaThumbImage(thumbnailSize, thumbDots)
+	tImageBase64, err = cc.genCaptchaThumbImage(cfg, thumbnailSize, thumbDots)
 	if err != nil {
 		return nil, "", "", "", err
 	}
@@ -533,8 +864,20 @@ func (cc *Captcha) GenerateWithSize(imageSize Size, thumbnailSize Size) (map[int
  * @return string
  */
 func (cc *Captcha) EncodeB64stringWithJpeg(img image.Image) string {
-	if cc.config.imageQuality <= QualityCompressLevel1 && cc.config.imageQuality >= QualityCompressLevel1 {
-		return EncodeB64stringWithJpeg(img, cc.config.imageQuality)
+	cfg := cc.snapshot()
+	return cc.encodeB64stringWithJpeg(cfg, img)
+}
+
+/**
+ * @Description: 按配置快照的清晰度进行base64编码
+ * @receiver cc
+ * @param cfg
+ * @param img
+ * @return string
+ */
+func (cc *Captcha) encodeB64stringWithJpeg(cfg *Config, img image.Image) string {
+	if cfg.ImageQuality <= QualityCompressLevel1 && cfg.ImageQuality >= QualityCompressLevel1 {
+		return EncodeB64stringWithJpeg(img, cfg.ImageQuality)
 	}
 	return EncodeB64stringWithPng(img)
 }
@@ -566,13 +909,14 @@ func (cc *Captcha) genCaptchaKey(str string) (string, error) {
 /**
  * @Description: 生成字符在图片上的点
  * @receiver cc
+ * @param cov		字形覆盖，字体只在包含字符字形的字体中选择
  * @param imageSize
  * @param fontSize
  * @param chars
  * @param padding
  * @return []*CaptchaCharDot
  */
-func (cc *Captcha) genDots(imageSize Size, fontSize RangeVal, chars string, padding int) map[int]CharDot {
+func (cc *Captcha) genDots(cfg *Config, cov *glyphCoverage, imageSize Size, fontSize RangeVal, chars string, padding int) map[int]CharDot {
 	dots := make(map[int]CharDot) // 各个文字点位置
 	width := imageSize.Width
 	height := imageSize.Height
@@ -585,28 +929,43 @@ func (cc *Captcha) genDots(imageSize Size, fontSize RangeVal, chars string, padd
 	for i := 0; i < len(strs); i++ {
 		str := strs[i]
 		// 随机角度
-		randAngle := cc.getRandAngle()
+		randAngle := cc.getRandAngle(cfg)
 		// 随机颜色
-		randColor := cc.getRandColor(cc.config.rangFontColors)
-		randColor2 := cc.getRandColor(cc.config.rangThumbFontColors)
+		randColor := cc.getRandColor(cfg.RangFontColors)
+		randColor2 := cc.getRandColor(cfg.RangThumbFontColors)
 
 		// 随机文字大小
 		randFontSize := RandInt(fontSize.Min, fontSize.Max)
 		fontHeight := randFontSize
 		fontWidth := randFontSize
+		var randFont, icon string
 
-		if LenChineseChar(str) > 1 {
-			fontWidth = randFontSize * LenChineseChar(str)
-
-			if randAngle > 0 {
-				surplus := fontWidth - randFontSize
-				ra := randAngle % 90
-				pr := float64(surplus) / 90
-				h := math.Max(float64(ra)*pr, 1)
-				fontHeight = fontHeight + int(h)
+		if cfg.TextMode == TextModeIcon {
+			// 图标按比例缩放，长边为字号
+			icon = iconPath(cfg, str)
+			if w, h, err := iconSize(icon, randFontSize); err == nil {
+				fontWidth, fontHeight = w, h
+			}
+		} else {
+			// 按字体的步进宽度及上下高度计算，字体无法读取时按字号估算
+			randFont = cc.genRandWithString(cov.fontsFor(str))
+			if tm, err := measureText(cfg, randFont, str, randFontSize, cfg.FontDPI); err == nil {
+				fontWidth = max(tm.Advance, 1)
+				fontHeight = max(tm.Height(), 1)
+			} else if LenChineseChar(str) > 1 {
+				fontWidth = randFontSize * LenChineseChar(str)
 			}
 		}
 
+		// 宽文本旋转后需要更高的画布
+		if fontWidth > fontHeight && randAngle > 0 {
+			surplus := fontWidth - fontHeight
+			ra := randAngle % 90
+			pr := float64(surplus) / 90
+			h := math.Max(float64(ra)*pr, 1)
+			fontHeight = fontHeight + int(h)
+		}
+
 		//_w := (width - randFontSize) / len(str)
 		_w := width / len(strs)
 		rd := math.Abs(float64(_w) - float64(fontWidth))
@@ -616,7 +975,7 @@ func (cc *Captcha) genDots(imageSize Size, fontSize RangeVal, chars string, padd
 		y = int(math.Min(math.Max(float64(y), float64(fontHeight+10)), float64(height+(fontHeight/2)-(padding*2))))
 		text := fmt.Sprintf("%s", str)
 
-		dot := CharDot{i, x, y, randFontSize, fontWidth, fontHeight, text, randAngle, randColor, randColor2}
+		dot := CharDot{i, x, y, randFontSize, fontWidth, fontHeight, text, randAngle, randColor, randColor2, randFont, icon}
 		dots[i] = dot
 	}
 
@@ -624,52 +983,69 @@ func (cc *Captcha) genDots(imageSize Size, fontSize RangeVal, chars string, padd
 }
 
 /**
- * @Description: 随机检测点
+ * @Description: 随机检测点，验证文本之间不会是形近字
  * @receiver cc
  * @param dots
  * @return map[int]CaptchaCharDot
  * @return string
  */
-func (cc *Captcha) rangeCheckDots(dots map[int]CharDot) (map[int]CharDot, string) {
-	rand.Seed(time.Now().UnixNano())
-	rs := rand.Perm(len(dots))
+func (cc *Captcha) rangeCheckDots(cfg *Config, dots map[int]CharDot) (map[int]CharDot, string) {
+	rs := RandPerm(len(dots))
 	chkDots := make(map[int]CharDot)
-	count := RandInt(cc.config.rangCheckTextLen.Min, cc.config.rangCheckTextLen.Max)
+	count := RandInt(cfg.RangCheckTextLen.Min, cfg.RangCheckTextLen.Max)
 	var chars []string
-	for i, value := range rs {
-		if i >= count {
+	for _, value := range rs {
+		if len(chars) >= count {
+			break
+		}
+		// 验证文本之间不能是形近字，互不相似的文本不足时减少验证文本数量
+		if hasSimilarChar(chars, dots[value].Text) {
 			continue
 		}
-		dot := dots[value]
-		dot.Index = i
-		chkDots[i] = dot
-		chars = append(chars, chkDots[i].Text)
+		chars = cc.appendCheckDot(chkDots, chars, dots[value])
 	}
 	return chkDots, strings.Join(chars, ":")
 }
 
+/**
+ * @Description: 按顺序添加验证点
+ * @receiver cc
+ * @param chkDots
+ * @param chars
+ * @param dot
+ * @return []string
+ */
+func (cc *Captcha) appendCheckDot(chkDots map[int]CharDot, chars []string, dot CharDot) []string {
+	dot.Index = len(chars)
+	chkDots[dot.Index] = dot
+	return append(chars, dot.Text)
+}
+
 /**
  * @Description: 验证码画图
  * @receiver cc
  * @param size
  * @param dots
+ * @param background	背景图
+ * @param cut			背景图裁剪位置
  * @return string
  * @return error
  */
-func (cc *Captcha) genCaptchaImage(size Size, dots map[int]CharDot) (base64 string, erro error) {
+func (cc *Captcha) genCaptchaImage(cfg *Config, size Size, dots map[int]CharDot, bgImg image.Image, cut image.Point) (base64 string, erro error) {
 	var drawDots []DrawDot
 	for _, dot := range dots {
 		drawDot := DrawDot{
 			Dx:      dot.Dx,
 			Dy:      dot.Dy,
-			FontDPI: cc.config.fontDPI,
+			FontDPI: cfg.FontDPI,
 			Text:    dot.Text,
 			Angle:   dot.Angle,
 			Color:   dot.Color,
 			Size:    dot.Size,
 			Width:   dot.Width,
 			Height:  dot.Height,
-			Font:    cc.genRandWithString(cc.config.rangFont),
+			Font:    dot.Font,
+			Icon:    dot.Icon,
 		}
 
 		drawDots = append(drawDots, drawDot)
@@ -678,15 +1054,24 @@ func (cc *Captcha) genCaptchaImage(size Size, dots map[int]CharDot) (base64 stri
 	img, err := cc.captchaDraw.Draw(DrawCanvas{
 		Width:             size.Width,
 		Height:            size.Height,
-		Background:        cc.genRandWithString(cc.config.rangBackground),
-		BackgroundDistort: cc.getRandDistortWithLevel(cc.config.imageFontDistort),
-		TextAlpha:         cc.config.imageFontAlpha,
-		FontHinting:       cc.config.fontHinting,
+		BackgroundImage:   bgImg,
+		BackgroundCut:     &cut,
+		BackgroundDistort: cc.getRandDistortWithLevel(cfg.ImageFontDistort),
+		TextAlpha:         cfg.ImageFontAlpha,
+		FontHinting:       cfg.FontHinting,
 		CaptchaDrawDot:    drawDots,
 
-		ShowTextShadow:  cc.config.showTextShadow,
-		TextShadowColor: cc.config.textShadowColor,
-		TextShadowPoint: cc.config.textShadowPoint,
+		ShowTextShadow:  cfg.ShowTextShadow,
+		TextShadowColor: cfg.TextShadowColor,
+		TextShadowPoint: cfg.TextShadowPoint,
+
+		Interference: Interference{
+			WaveLines:    cfg.ImageWaveLineNum,
+			Arcs:         cfg.ImageArcNum,
+			NoiseDots:    cfg.ImageNoiseDotNum,
+			DecoyStrokes: cfg.ImageDecoyStrokeNum,
+			Alpha:        cfg.ImageInterferenceAlpha,
+		},
 	})
 	if err != nil {
 		erro = err
@@ -694,7 +1079,7 @@ func (cc *Captcha) genCaptchaImage(size Size, dots map[int]CharDot) (base64 stri
 	}
 
 	// 转 base64
-	base64 = cc.EncodeB64stringWithJpeg(img)
+	base64 = cc.encodeB64stringWithJpeg(cfg, img)
 	return
 }
 
@@ -706,25 +1091,31 @@ func (cc *Captcha) genCaptchaImage(size Size, dots map[int]CharDot) (base64 stri
  * @return string
  * @return error
  */
-func (cc *Captcha) genCaptchaThumbImage(size Size, dots map[int]CharDot) (string, error) {
+func (cc *Captcha) genCaptchaThumbImage(cfg *Config, size Size, dots map[int]CharDot) (string, error) {
 	var drawDots []DrawDot
 
 	fontWidth := size.Width / len(dots)
 	for i, dot := range dots {
 		Dx := int(math.Max(float64(fontWidth*i+fontWidth/dot.Width), 8))
-		Dy := size.Height/2 + dot.Size/2 - rand.Intn(size.Height/16*len(dot.Text))
+		Dy := size.Height/2 + dot.Size/2 - RandInt(0, size.Height/16*len(dot.Text)-1)
+		// 图标以 Dy 为底边，在缩略图中垂直居中
+		if dot.Icon != "" {
+			Dx = int(math.Max(float64(fontWidth*i+(fontWidth-dot.Width)/2), 0))
+			Dy = (size.Height + dot.Height) / 2
+		}
 
 		drawDot := DrawDot{
 			Dx:      Dx,
 			Dy:      Dy,
-			FontDPI: cc.config.fontDPI,
+			FontDPI: cfg.FontDPI,
 			Text:    dot.Text,
 			Angle:   dot.Angle,
 			Color:   dot.Color2,
 			Size:    dot.Size,
 			Width:   dot.Width,
 			Height:  dot.Height,
-			Font:    cc.genRandWithString(cc.config.rangFont),
+			Font:    dot.Font,
+			Icon:    dot.Icon,
 		}
 		drawDots = append(drawDots, drawDot)
 	}
@@ -733,23 +1124,25 @@ func (cc *Captcha) genCaptchaThumbImage(size Size, dots map[int]CharDot) (string
 		Width:                 size.Width,
 		Height:                size.Height,
 		CaptchaDrawDot:        drawDots,
-		BackgroundDistort:     cc.getRandDistortWithLevel(cc.config.thumbFontDistort),
-		BackgroundCirclesNum:  cc.config.thumbBgCirclesNum,
-		BackgroundSlimLineNum: cc.config.thumbBgSlimLineNum,
+		BackgroundDistort:     cc.getRandDistortWithLevel(cfg.ThumbFontDistort),
+		BackgroundCirclesNum:  cfg.ThumbBgCirclesNum,
+		BackgroundSlimLineNum: cfg.ThumbBgSlimLineNum,
 	}
 
-	if len(cc.config.rangThumbBackground) > 0 {
-		params.Background = cc.genRandWithString(cc.config.rangThumbBackground)
+	if len(cfg.RangThumbBackground) > 0 {
+		params.Background = cc.genRandWithString(cfg.RangThumbBackground)
+	} else {
+		params.BackgroundColor = cfg.ThumbBgFillColor
 	}
 
 	var colorA []color.Color
-	for _, cStr := range cc.config.rangThumbFontColors {
+	for _, cStr := range cfg.RangThumbFontColors {
 		co, _ := ParseHexColor(cStr)
 		colorA = append(colorA, co)
 	}
 
 	var colorB []color.Color
-	for _, co := range cc.config.rangThumbBgColors {
+	for _, co := range cfg.RangThumbBgColors {
 		rc, _ := ParseHexColor(co)
 		colorB = append(colorB, rc)
 	}
@@ -790,8 +1183,8 @@ func (cc *Captcha) getRandDistortWithLevel(level int) int {
  * @receiver cc
  * @return int
  */
-func (cc *Captcha) getRandAngle() int {
-	angles := cc.config.rangTexAnglePos
+func (cc *Captcha) getRandAngle(cfg *Config) int {
+	angles := cfg.RangTexAnglePos
 	anglesLen := len(angles)
 	index := RandInt(0, anglesLen)
 	if index >= anglesLen {
@@ -820,17 +1213,21 @@ func (cc *Captcha) getRandColor(colors []string) string {
 }
 
 /**
- * @Description: 随机生成中文字符串
+ * @Description: 随机生成不重复且互不相似的字符串，互不相似的字符不足时返回的字符少于 length，不会放入形近字
+ * @param chars
  * @param length
  * @return string
  */
-func (cc *Captcha) genRandChar(length int) string {
+func (cc *Captcha) genRandChar(chars []string, length int) string {
 	var strA []string
-	for len(strA) < length {
-		char := cc.randChar()
-		if !InArrayWithStr(strA, char) {
-			strA = append(strA, char)
+	for _, i := range RandPerm(len(chars)) {
+		if len(strA) >= length {
+			break
+		}
+		if hasSimilarChar(strA, chars[i]) {
+			continue
 		}
+		strA = append(strA, chars[i])
 	}
 
 	return strings.Join(strA, ":")
@@ -857,135 +1254,140 @@ func (cc *Captcha) genRandWithString(strs []string) string {
 
 /**
  * @Description: 随机一个字符
+ * @param chars
  * @return string
  */
-func (cc *Captcha) randChar() string {
-	chars := *cc.chars
-	k := rand.Intn(len(chars))
+func (cc *Captcha) randChar(chars []string) string {
+	k := RandInt(0, len(chars)-1)
 	return chars[k]
 }
 
+// InitConfig is a function
+/**
+ * @Description: 写入默认路由的配置，默认配置由程序决定，任何设置失败都会 panic 并列出全部错误
+ * @return *Captcha
+ */
 func InitConfig() *Captcha {
 	capt := GetCaptcha()
-	// path, _ := os.Getwd()
-	// path:="https://github.com/hulutech-web/goravel-captcha/blob/master"
-	path:="https://raw.githubusercontent.com/hulutech-web/goravel-captcha/master"
-	//// ====================================================
-	//// Method: SetBackground(color []string);
-	//// Desc: Set random image of background
-	//  net work url
-	//// ====================================================
-	u1, _ := url.Parse(fmt.Sprintf("%s/resources/images/1.jpg", path))
-	u2, _ := url.Parse(fmt.Sprintf("%s/resources/images/2.jpg", path))
-	u3, _ := url.Parse(fmt.Sprintf("%s/resources/images/3.jpg", path))
-	u4, _ := url.Parse(fmt.Sprintf("%s/resources/images/4.jpg", path))
-	capt.SetBackground([]string{
-		u1,u2, u3, u4,
-	})
+	var errs []error
+	// ====================================================
+	// Method: SetBackgroundFS(fsys fs.FS, patterns ...string);
+	// Desc: Set random image of background, embedded in the module
+	// ====================================================
+	errs = append(errs, capt.SetBackgroundFS(resources.Images, "images/*.jpg"))
 
 	// ====================================================
 	// Method: SetImageSize(size Size);
 	// Desc: Set size of captcha
 	// ====================================================
-	capt.SetImageSize(Size{300, 240})
+	errs = append(errs, capt.SetImageSize(Size{300, 240}))
 
 	// ====================================================
 	// Method: SetImageQuality(val int);
 	// Desc: Set quality of captcha, The compression level ranges from 1 to 5. QualityCompressNone has no compression. The default is the lowest compression level
 	// ====================================================
-	capt.SetImageQuality(QualityCompressNone)
+	errs = append(errs, capt.SetImageQuality(QualityCompressNone))
 
 	// ====================================================
 	// Method: SetFontHinting(val font.Hinting);
 	// Desc: Set Hinting of font (HintingNone,HintingVertical,HintingFull)
 	// ====================================================
-	capt.SetFontHinting(font.HintingFull)
+	errs = append(errs, capt.SetFontHinting(font.HintingFull))
 
 	// ====================================================
 	// Method: SetTextRangLen(val captcha.RangeVal);
 	// Desc: Set random length of font
 	// ====================================================
-	capt.SetTextRangLen(RangeVal{6, 7})
+	errs = append(errs, capt.SetTextRangLen(RangeVal{6, 7}))
 
 	// ====================================================
 	// Method: SetRangFontSize(val captcha.RangeVal);
 	// Desc: Set random size of font
 	// ====================================================
-	capt.SetRangFontSize(RangeVal{32, 42})
+	errs = append(errs, capt.SetRangFontSize(RangeVal{32, 42}))
 
 	// ====================================================
 	// Method: SetTextRangFontColors(colors []string);
 	// Desc: Set random hex color of font
 	// ====================================================
-	capt.SetTextRangFontColors([]string{
+	errs = append(errs, capt.SetTextRangFontColors([]string{
 		"#1d3f84",
 		"#3a6a1e",
-	})
+	}))
 
 	// ====================================================
 	// Method: SetImageFontAlpha(val float64);
 	// Desc:Set alpha of font
 	// ====================================================
-	capt.SetImageFontAlpha(0.5)
+	errs = append(errs, capt.SetImageFontAlpha(0.5))
 
 	// ====================================================
 	// Method: SetTextShadow(val bool);
 	// Desc:Set shadow of font
 	// ====================================================
-	capt.SetTextShadow(true)
+	errs = append(errs, capt.SetTextShadow(true))
 	chars := []string{"你", "好", "呀", "这", "是", "点", "击", "验", "证", "码", "哟"}
-	_ = capt.SetRangChars(chars)
+	errs = append(errs, capt.SetRangChars(chars))
 	// ====================================================
 	// Method: SetTextShadowColor(val string);
 	// Desc:Set shadow color of font
 	// ====================================================
-	capt.SetTextShadowColor("#101010")
+	errs = append(errs, capt.SetTextShadowColor("#101010"))
 
 	// ====================================================
 	// Method: SetTextShadowPoint(val captcha.Point);
 	// Desc:Set shadow point of font
 	// ====================================================
-	capt.SetTextShadowPoint(Point{1, 1})
+	errs = append(errs, capt.SetTextShadowPoint(Point{1, 1}))
 
 	// ====================================================
 	// Method: SetTextRangAnglePos(pos []captcha.RangeVal);
 	// Desc:Set angle of font
 	// ====================================================
-	capt.SetTextRangAnglePos([]RangeVal{
+	errs = append(errs, capt.SetTextRangAnglePos([]RangeVal{
 		{1, 15},
 		{15, 30},
 		{30, 45},
 		{315, 330},
 		{330, 345},
 		{345, 359},
-	})
+	}))
 
 	// ====================================================
 	// Method: SetImageFontDistort(val int);
 	// Desc:Set distort of font
 	// ====================================================
-	capt.SetImageFontDistort(DistortLevel2)
+	errs = append(errs, capt.SetImageFontDistort(DistortLevel2))
 	//缓存数据
 	//加入缓存
+	if err := errors.Join(errs...); err != nil {
+		panic(err)
+	}
 
 	return capt
 }
+
+// VerifyCaptcha is a function
+/**
+ * @Description: 使用默认配置校验验证码
+ * @param key
+ * @param dots
+ * @return bool
+ */
 func VerifyCaptcha(key, dots string) bool {
-	if dots == "" || key == "" {
-		return false
-	}
+	return VerifyCaptchaWithProfile(DefaultProfile, key, dots)
+}
 
-	cacheData := readCache(key)
-	if cacheData == "" {
-		return false
-	}
+/**
+ * @Description: 校验提交的点位置是否落在缓存的文本区域内
+ * @param dct	缓存的校验点
+ * @param dots	提交的点位置，格式："x1,y1,x2,y2"
+ * @param padding	校验区域扩充的像素
+ * @return bool
+ */
+func checkCacheDots(dct map[int]CharDot, dots string, padding int) bool {
 	src := strings.Split(dots, ",")
 
-	var dct map[int]captcha.CharDot
-	if err := json.Unmarshal([]byte(cacheData), &dct); err != nil {
-		return false
-	}
-
 	chkRet := false
 	if (len(dct) * 2) == len(src) {
 		for i, dot := range dct {
@@ -999,17 +1401,14 @@ func VerifyCaptcha(key, dots string) bool {
 
 			// 校验点的位置,在原有的区域上添加额外边距进行扩张计算区域,不推荐设置过大的padding
 			// 例如：文本的宽和高为30，校验范围x为10-40，y为15-45，此时扩充5像素后校验范围宽和高为40，则校验范围x为5-45，位置y为10-50
-			chkRet = captcha.CheckPointDistWithPadding(int64(sx), int64(sy), int64(dot.Dx), int64(dot.Dy), int64(dot.Width), int64(dot.Height), 5)
+			chkRet = captcha.CheckPointDistWithPadding(int64(sx), int64(sy), int64(dot.Dx), int64(dot.Dy), int64(dot.Width), int64(dot.Height), int64(padding))
 			if !chkRet {
 				break
 			}
 		}
 	}
 
-	if chkRet {
-		return true
-	}
-	return false
+	return chkRet
 }
 
 // 缓存数据
@@ -1091,11 +1490,15 @@ func RunTimedTask() {
 }
 
 /**
- * @Description: 检查缓存超时文件， 5分钟
+ * @Description: 检查缓存超时文件，超过所有配置中最长的有效期后删除，默认5分钟
  */
 func checkCacheOvertimeFile() {
 	files, files1, _ := listDir(getCacheDir())
 	for _, table := range files1 {
+		// 失败记录按半衰期衰减，由 FailureTracker 自行清理
+		if filepath.Base(table) == failureDir {
+			continue
+		}
 		temp, _, _ := listDir(table)
 		for _, temp1 := range temp {
 			files = append(files, temp1)
@@ -1103,10 +1506,11 @@ func checkCacheOvertimeFile() {
 	}
 	fmt.Printf("files: %v, files1: %v\n", files, files1)
 
+	ttl := int64(maxProfileTTL().Seconds())
 	for _, file := range files {
 		t := GetFileCreateTime(file)
 		ex := time.Now().Unix() - t
-		if ex > (60 * 5) {
+		if ex > ttl {
 			err := os.Remove(file)
 			if err != nil {
 				fmt.Println("error:", err)
@@ -1150,15 +1554,34 @@ func listDir(dirPth string) (files []string, files1 []string, err error) {
 	return files, files1, nil
 }
 
+// InitCaptcha is a function
+/**
+ * @Description: 获取按默认路由配置初始化的验证码，配置只在首次调用时写入，之后的请求只读取配置快照
+ * @return *Captcha
+ */
+func InitCaptcha() *Captcha {
+	_initOnce.Do(func() {
+		capt := InitConfig()
+		// 默认路由主图 2-5 个字符，需满足 RangCheckTextLen.Max <= RangTextLen.Min，验证文本固定 2 个
+		if err := errors.Join(
+			capt.SetTextRangLen(RangeVal{2, 5}),
+			capt.SetRangCheckTextLen(RangeVal{2, 2}),
+		); err != nil {
+			panic(err)
+		}
+	})
+	return GetCaptcha()
+}
+
+// MakeCaptcha is a function
+/**
+ * @Description: 使用默认配置生成验证码并写入缓存
+ * @return interface{}	位置信息
+ * @return string		主图Base64
+ * @return string		缩略图Base64
+ * @return string		验证码KEY
+ * @return error
+ */
 func MakeCaptcha() (interface{}, string, string, string, error) {
-	capt := InitConfig()
-	capt.SetTextRangLen(RangeVal{2, 5})
-	// 生成验证码
-	dots, b64, tb64, key, err := capt.Generate()
-	if err != nil {
-		return "", "", "", "", err
-	}
-	//加入缓存
-	writeCache(dots, key)
-	return dots, b64, tb64, key, nil
+	return MakeCaptchaWithProfile(DefaultProfile)
 }
@@ -0,0 +1,211 @@
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 验证码记录写入工作目录下的 .cache，测试在临时目录中运行
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "captcha-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// 点击每个校验区域的中心
+func clickDots(dots map[int]CharDot) string {
+	points := make([]string, 0, len(dots)*2)
+	for i := 0; i < len(dots); i++ {
+		d := dots[i]
+		points = append(points, fmt.Sprint(d.Dx+d.Width/2), fmt.Sprint(d.Dy-d.Height/2))
+	}
+	return strings.Join(points, ",")
+}
+
+// 注册测试使用的命名配置，测试结束后移除
+func registerTestProfile(t *testing.T, name string, cc *Captcha) {
+	t.Helper()
+	if err := RegisterProfile(name, cc, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_profilesMu.Lock()
+		delete(_profiles, name)
+		_profilesMu.Unlock()
+	})
+}
+
+func TestConcurrentGenerateVerify(t *testing.T) {
+	cc := newTestCaptcha(t)
+	registerTestProfile(t, DefaultProfile, cc)
+	all := cc.Config().RangChars
+	sizes := []Size{{300, 240}, {320, 260}}
+	charsets := [][]string{all, all[:8]}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 8; i++ {
+				dots, _, _, key, err := MakeCaptcha()
+				if err != nil {
+					errs <- err
+					return
+				}
+				// 生成期间配置被替换，校验仍按签发时的快照进行
+				if !VerifyCaptcha(key, clickDots(dots.(map[int]CharDot))) {
+					errs <- fmt.Errorf("VerifyCaptcha(%s) failed for the issued dots", key)
+					return
+				}
+				if VerifyCaptcha(key, clickDots(dots.(map[int]CharDot))) {
+					errs <- fmt.Errorf("VerifyCaptcha(%s) accepted a used key", key)
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 16; i++ {
+			if err := cc.SetImageSize(sizes[i%2]); err != nil {
+				errs <- err
+				return
+			}
+			if err := cc.SetRangChars(charsets[i%2]); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 8; i++ {
+			cfg := cc.Config()
+			if _, _, _, _, err := cc.GenerateWithSize(cfg.ImageSize, cfg.ThumbnailSize); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestInitConfigEmbeddedBackgrounds(t *testing.T) {
+	// InitConfig 修改单例的配置，测试结束后恢复
+	prev := GetCaptcha().Config()
+	t.Cleanup(func() {
+		if err := GetCaptcha().SetConfig(prev); err != nil {
+			t.Error(err)
+		}
+	})
+	cc := InitConfig()
+	cfg := cc.Config()
+	if len(cfg.RangBackground) == 0 {
+		t.Fatal("InitConfig() set no background")
+	}
+	// 内置背景图已写入资源缓存，不需要网络访问
+	for _, file := range cfg.RangBackground {
+		if _, err := getAssetImage(file); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInitCaptchaDefaultRoute(t *testing.T) {
+	cc := InitCaptcha()
+	cfg := cc.Config()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RangTextLen != (RangeVal{2, 5}) || cfg.RangCheckTextLen != (RangeVal{2, 2}) {
+		t.Fatalf("RangTextLen = %v, RangCheckTextLen = %v; want {2 5} and {2 2}", cfg.RangTextLen, cfg.RangCheckTextLen)
+	}
+	dots, _, _, _, err := cc.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dots) < cfg.RangCheckTextLen.Min || len(dots) > cfg.RangCheckTextLen.Max {
+		t.Fatalf("Generate() checks %d chars, want %v", len(dots), cfg.RangCheckTextLen)
+	}
+}
+
+func TestRejectedOptionKeepsAssets(t *testing.T) {
+	content, err := getAssetCache(DefaultConfig().RangFont[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "font.ttf")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearAssetCache([]string{path}) })
+
+	// 被拒绝的选项读取的文件不写入资源缓存
+	if _, err := NewCaptcha(WithFont([]string{path}), WithRangChars([]string{""})); err == nil {
+		t.Fatal("NewCaptcha() with an empty char did not fail")
+	}
+	if hasAssetCache(path) {
+		t.Fatalf("rejected option cached [%s]", path)
+	}
+
+	cc := newTestCaptcha(t)
+	if err := cc.SetFont([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+	if !hasAssetCache(path) {
+		t.Fatalf("[%s] is not cached after the option applied", path)
+	}
+
+	// 强制重新读取但被拒绝时保留原来的字体
+	if err := os.WriteFile(path, []byte("not a font"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cc.SetFont([]string{path}, true); err == nil {
+		t.Fatal("SetFont() with a broken font did not fail")
+	}
+	if got, _ := getAssetCache(path); !bytes.Equal(got, content) {
+		t.Fatal("rejected SetFont() replaced the cached font")
+	}
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkGenerateWithSize(b *testing.B) {
+	cc, err := NewCaptcha()
+	if err != nil {
+		b.Fatal(err)
+	}
+	cfg := cc.Config()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, _, err := cc.GenerateWithSize(cfg.ImageSize, cfg.ThumbnailSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package instance
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rivo/uniseg"
+)
+
+/**
+ * @Description: 内置字符集名称
+ */
+const (
+	// 常用简体中文，GB2312 一级汉字
+	CharsetSimplified = "simplified"
+	// 常用繁体中文，Big5 常用字，需要配合包含繁体字形的字体使用
+	CharsetTraditional = "traditional"
+	// 拉丁字母，去除 0/O、1/l/I 等易混淆字母
+	CharsetLatin = "latin"
+	// 数字
+	CharsetDigits = "digits"
+	// 日文假名，平假名及片假名
+	CharsetKana = "kana"
+)
+
+// Charset is a type
+/**
+ * @Description: 命名字符集，Exclude 中的字符即使在 Chars 中也不会被使用
+ */
+type Charset struct {
+	// 字符集名称
+	Name string
+	// 字符集合
+	Chars []string
+	// 排除的字符，字形与其他字符过于相似
+	Exclude []string
+}
+
+var (
+	_charsets   = make(map[string]Charset)
+	_charsetsMu sync.RWMutex
+)
+
+func init() {
+	// 简体字中与常用字字形几乎相同的字
+	_ = RegisterCharset(Charset{
+		Name:    CharsetSimplified,
+		Chars:   splitChars(charsetGB2312Level1),
+		Exclude: []string{"曰", "巳", "戊", "戌", "戍", "毋", "兀", "汩", "汨", "冗", "刁"},
+	})
+	// 繁体字中与常用字字形几乎相同的字，以及繁体特有的 烏/鳥、間/閒/問、晝/書 等
+	_ = RegisterCharset(Charset{
+		Name:  CharsetTraditional,
+		Chars: splitChars(charsetBig5Level1),
+		Exclude: []string{
+			"曰", "巳", "戊", "戌", "戍", "毋", "兀", "汨", "冗", "刁",
+			"烏", "間", "閒", "晝", "柝", "孑", "孓", "辮",
+		},
+	})
+	// 大小写字形相同的字母只保留大写，去除与数字或其他字母相似的字母
+	_ = RegisterCharset(Charset{
+		Name:  CharsetLatin,
+		Chars: splitChars("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"),
+		Exclude: []string{
+			"O", "Q", "I", "l", "i", "j", "o",
+			"c", "k", "p", "s", "u", "v", "w", "x", "z",
+		},
+	})
+	// 验证码文本会旋转，6 和 9 容易混淆，剩余 8 个字符中 3/8 为形近字，只有 7 组互不相似
+	_ = RegisterCharset(Charset{
+		Name:    CharsetDigits,
+		Chars:   splitChars("0123456789"),
+		Exclude: []string{"6", "9"},
+	})
+	// 去除小写假名、长音符及平假名与片假名之间、假名与汉字之间字形相同或相近的字符
+	_ = RegisterCharset(Charset{
+		Name: CharsetKana,
+		Chars: splitChars("あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわをん" +
+			"アイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワヲン"),
+		Exclude: []string{"へ", "ヘ", "り", "リ", "ロ", "ニ", "ハ", "エ", "カ", "タ", "ト", "ソ", "ン", "シ", "ツ", "ノ", "チ", "ミ"},
+	})
+}
+
+// RegisterCharset is a function
+/**
+ * @Description: 注册字符集，同名字符集会被替换
+ * @param cs
+ * @return error
+ */
+func RegisterCharset(cs Charset) error {
+	if cs.Name == "" {
+		return fmt.Errorf("Captcha Charset Error: The name must not be empty")
+	}
+	if err := checkChars(cs.Chars); err != nil {
+		return err
+	}
+
+	_charsetsMu.Lock()
+	defer _charsetsMu.Unlock()
+	_charsets[cs.Name] = Charset{
+		Name:    cs.Name,
+		Chars:   append([]string(nil), cs.Chars...),
+		Exclude: append([]string(nil), cs.Exclude...),
+	}
+	return nil
+}
+
+// GetCharset is a function
+/**
+ * @Description: 获取字符集
+ * @param name
+ * @return Charset
+ * @return bool
+ */
+func GetCharset(name string) (Charset, bool) {
+	_charsetsMu.RLock()
+	defer _charsetsMu.RUnlock()
+
+	cs, ok := _charsets[name]
+	return cs, ok
+}
+
+// CharsetChars is a function
+/**
+ * @Description: 合并多个字符集，去除各自的排除字符及重复字符
+ * @param names
+ * @return []string
+ * @return error
+ */
+func CharsetChars(names ...string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("Captcha Charset Error: At least one charset is required")
+	}
+
+	var chars []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		cs, ok := GetCharset(name)
+		if !ok {
+			return nil, fmt.Errorf("Captcha Charset Error: The [%s] charset does not exist", name)
+		}
+
+		exclude := make(map[string]bool, len(cs.Exclude))
+		for _, ch := range cs.Exclude {
+			exclude[ch] = true
+		}
+		for _, ch := range cs.Chars {
+			if exclude[ch] || seen[ch] {
+				continue
+			}
+			seen[ch] = true
+			chars = append(chars, ch)
+		}
+	}
+	return chars, nil
+}
+
+// WithCharset is a function
+/**
+ * @Description: 使用内置或已注册的字符集作为随机字符集合，多个字符集合并使用
+ * @param names
+ * @return Option
+ */
+func WithCharset(names ...string) Option {
+	return func(c *Config) error {
+		chars, err := CharsetChars(names...)
+		if err != nil {
+			return err
+		}
+		return WithRangChars(chars)(c)
+	}
+}
+
+// SetCharset is a function
+/**
+ * @Description: 设置随机字符集合为内置或已注册的字符集
+ * @receiver cc
+ * @param names
+ * @return error
+ */
+func (cc *Captcha) SetCharset(names ...string) error {
+	return cc.applyGlyphs(WithCharset(names...))
+}
+
+/**
+ * @Description: 按字素簇拆分字符串，组合字符序列不会被拆开
+ * @param str
+ * @return []string
+ */
+func splitChars(str string) []string {
+	chars := make([]string, 0, len(str))
+	g := uniseg.NewGraphemes(str)
+	for g.Next() {
+		chars = append(chars, g.Str())
+	}
+	return chars
+}
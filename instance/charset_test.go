@@ -0,0 +1,105 @@
+package instance
+
+import (
+	"testing"
+)
+
+func TestBuiltinCharsets(t *testing.T) {
+	for _, name := range []string{CharsetSimplified, CharsetTraditional, CharsetLatin, CharsetDigits, CharsetKana} {
+		cs, ok := GetCharset(name)
+		if !ok {
+			t.Fatalf("the [%s] charset is not registered", name)
+		}
+		chars, err := CharsetChars(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chars) == 0 || checkChars(chars) != nil {
+			t.Fatalf("the [%s] charset is empty or has invalid chars", name)
+		}
+		// 排除的字符不会被使用
+		for _, ch := range cs.Exclude {
+			if InArrayWithStr(chars, ch) {
+				t.Errorf("the [%s] charset kept the excluded char [%s]", name, ch)
+			}
+		}
+	}
+}
+
+func TestCharsetExclusions(t *testing.T) {
+	traditional, err := CharsetChars(CharsetTraditional)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 繁体特有的形近字
+	for _, ch := range []string{"烏", "間", "閒", "晝"} {
+		if InArrayWithStr(traditional, ch) {
+			t.Errorf("traditional kept the confusable char [%s]", ch)
+		}
+	}
+	for _, ch := range []string{"鳥", "問", "書"} {
+		if !InArrayWithStr(traditional, ch) {
+			t.Errorf("traditional lost the char [%s]", ch)
+		}
+	}
+
+	digits, err := CharsetChars(CharsetDigits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digits) != 8 || InArrayWithStr(digits, "6") || InArrayWithStr(digits, "9") {
+		t.Errorf("digits = %v, want 8 chars without 6 and 9", digits)
+	}
+}
+
+func TestCharsetChars(t *testing.T) {
+	latin, err := CharsetChars(CharsetLatin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range []string{"O", "I", "l"} {
+		if InArrayWithStr(latin, ch) {
+			t.Errorf("latin kept the confusable char [%s]", ch)
+		}
+	}
+
+	// 合并时去除重复字符
+	both, err := CharsetChars(CharsetLatin, CharsetDigits, CharsetLatin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, _ := CharsetChars(CharsetDigits)
+	if len(both) != len(latin)+len(digits) {
+		t.Fatalf("CharsetChars() = %d chars, want %d", len(both), len(latin)+len(digits))
+	}
+
+	if _, err := CharsetChars("missing"); err == nil {
+		t.Fatal("CharsetChars() with an unknown charset did not fail")
+	}
+	if _, err := CharsetChars(); err == nil {
+		t.Fatal("CharsetChars() without a name did not fail")
+	}
+}
+
+func TestRegisterCharset(t *testing.T) {
+	name := "test-vowels"
+	if err := RegisterCharset(Charset{Name: name, Chars: splitChars("AEUYBCDFGH"), Exclude: []string{"Y"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_charsetsMu.Lock()
+		delete(_charsets, name)
+		_charsetsMu.Unlock()
+	})
+	if err := RegisterCharset(Charset{Chars: []string{"A"}}); err == nil {
+		t.Fatal("RegisterCharset() without a name did not fail")
+	}
+
+	cc := newTestCaptcha(t, WithCharset(name))
+	if chars := cc.Config().RangChars; len(chars) != 9 || InArrayWithStr(chars, "Y") {
+		t.Fatalf("RangChars = %v", chars)
+	}
+	if err := cc.SetCharset("missing"); err == nil {
+		t.Fatal("SetCharset() with an unknown charset did not fail")
+	}
+}
@@ -0,0 +1,83 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigClone(t *testing.T) {
+	cfg := DefaultConfig()
+	c := cfg.Clone()
+	if !c.Equal(cfg) {
+		t.Fatal("Clone() is not equal to the original")
+	}
+
+	// 切片字段深拷贝
+	c.RangChars[0] = "x"
+	c.RangFontColors[0] = "#000000"
+	c.RangTexAnglePos[0] = RangeVal{1, 2}
+	c.RangBackground = append(c.RangBackground, "extra.jpg")
+	if cfg.RangChars[0] == "x" || cfg.RangFontColors[0] == "#000000" || cfg.RangTexAnglePos[0] == (RangeVal{1, 2}) {
+		t.Fatal("modifying the clone changed the original")
+	}
+	if c.Equal(cfg) {
+		t.Fatal("Equal() did not see the changed slices")
+	}
+}
+
+func TestConfigEqual(t *testing.T) {
+	a, b := DefaultConfig(), DefaultConfig()
+	if !a.Equal(b) {
+		t.Fatal("two default configs are not equal")
+	}
+	b.ImageSize = Size{320, 260}
+	if a.Equal(b) {
+		t.Fatal("Equal() ignored ImageSize")
+	}
+	b = DefaultConfig()
+	b.RangFont = append(b.RangFont, "other.ttf")
+	if a.Equal(b) {
+		t.Fatal("Equal() ignored RangFont")
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	cfg, err := NewConfig(WithImageSize(Size{320, 260}), WithTextRangLen(RangeVal{4, 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ImageSize != (Size{320, 260}) || cfg.RangTextLen != (RangeVal{4, 5}) {
+		t.Fatalf("NewConfig() did not apply the options: %v, %v", cfg.ImageSize, cfg.RangTextLen)
+	}
+
+	// 返回所有选项的错误
+	_, err = NewConfig(WithImageQuality(200), WithImageFontDistort(9))
+	if err == nil || !strings.Contains(err.Error(), "ImageQuality") || !strings.Contains(err.Error(), "ImageFontDistort") {
+		t.Fatalf("NewConfig() = %v, want both option errors", err)
+	}
+	if _, err := NewCaptcha(WithImageQuality(200)); err == nil {
+		t.Fatal("NewCaptcha() with an invalid option did not fail")
+	}
+}
+
+func TestCaptchaConfigCopy(t *testing.T) {
+	cc := newTestCaptcha(t)
+	cfg := cc.Config()
+	cfg.RangChars[0] = "x"
+	if cc.Config().RangChars[0] == "x" {
+		t.Fatal("modifying Config() changed the captcha")
+	}
+
+	cfg = cc.Config()
+	cfg.ImageSize = Size{320, 260}
+	if err := cc.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cc.Config().ImageSize != (Size{320, 260}) {
+		t.Fatal("SetConfig() did not replace the config")
+	}
+	cfg.FontDPI = 0
+	if err := cc.SetConfig(cfg); err == nil || cc.Config().FontDPI == 0 {
+		t.Fatal("SetConfig() accepted an invalid config")
+	}
+}
@@ -0,0 +1,238 @@
+package instance
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// 调整亮度时每次变化的幅度
+const lightnessStep = 0.05
+
+/**
+ * @Description: 按背景为每个文本选择对比度最高的颜色，调色板中没有满足 TextContrast 的颜色时调整亮度，
+ * 选中的颜色写入 dots 及 answers 的 Color
+ * @receiver cc
+ * @param cfg
+ * @param bgImg		背景图
+ * @param cut		背景图裁剪位置
+ * @param dots		绘制用的点
+ * @param answers	校验用的点，Width/Height 为占位区域
+ */
+func (cc *Captcha) applyContrast(cfg *Config, bgImg image.Image, cut image.Point, dots map[int]CharDot, answers map[int]CharDot) {
+	palette := make([]color.RGBA, 0, len(cfg.RangFontColors))
+	for _, hex := range cfg.RangFontColors {
+		co, _ := ParseHexColor(hex)
+		palette = append(palette, co)
+	}
+
+	for i, ans := range answers {
+		bg := averageColor(bgImg, cut, image.Rect(ans.Dx, ans.Dy-ans.Height, ans.Dx+ans.Width, ans.Dy))
+		co := pickContrastColor(palette, bg, cfg.ImageFontAlpha, cfg.TextContrast)
+		hex := "#" + RgbToHex(int64(co.R), int64(co.G), int64(co.B))
+
+		ans.Color = hex
+		answers[i] = ans
+		dot := dots[i]
+		dot.Color = hex
+		dots[i] = dot
+	}
+}
+
+/**
+ * @Description: 从调色板中选择与背景对比度最高的颜色，未达到最低对比度时调整亮度
+ * @param palette
+ * @param bg		背景平均颜色
+ * @param alpha		文本透明度，按与背景混合后的颜色计算对比度
+ * @param minRatio	最低对比度
+ * @return color.RGBA
+ */
+func pickContrastColor(palette []color.RGBA, bg color.RGBA, alpha float64, minRatio float64) color.RGBA {
+	bgLum := relativeLuminance(bg)
+	ratio := func(co color.RGBA) float64 {
+		return contrastRatio(relativeLuminance(blendColor(co, bg, alpha)), bgLum)
+	}
+
+	best := palette[0]
+	bestRatio := ratio(best)
+	for _, co := range palette[1:] {
+		if r := ratio(co); r > bestRatio {
+			best, bestRatio = co, r
+		}
+	}
+	if bestRatio >= minRatio {
+		return best
+	}
+
+	// 背景较亮时变暗，否则变亮，直到满足最低对比度或到达黑白
+	h, s, l := rgbToHsl(best)
+	darken := bgLum > 0.18
+	for bestRatio < minRatio {
+		if darken {
+			l = math.Max(l-lightnessStep, 0)
+		} else {
+			l = math.Min(l+lightnessStep, 1)
+		}
+		best = hslToRgb(h, s, l)
+		bestRatio = ratio(best)
+		if l == 0 || l == 1 {
+			break
+		}
+	}
+	return best
+}
+
+/**
+ * @Description: 区域内背景的平均颜色
+ * @param img
+ * @param cut
+ * @param r		主图坐标中的区域
+ * @return color.RGBA
+ */
+func averageColor(img image.Image, cut image.Point, r image.Rectangle) color.RGBA {
+	b := img.Bounds()
+	r = r.Add(cut).Add(b.Min).Intersect(b)
+	if r.Empty() {
+		return color.RGBA{A: 0xff}
+	}
+
+	var sr, sg, sb, n uint64
+	// 隔点采样，区域内的平均颜色足够估算对比度
+	for y := r.Min.Y; y < r.Max.Y; y += 2 {
+		for x := r.Min.X; x < r.Max.X; x += 2 {
+			cr, cg, cb, _ := img.At(x, y).RGBA()
+			sr += uint64(cr >> 8)
+			sg += uint64(cg >> 8)
+			sb += uint64(cb >> 8)
+			n++
+		}
+	}
+	return color.RGBA{R: uint8(sr / n), G: uint8(sg / n), B: uint8(sb / n), A: 0xff}
+}
+
+/**
+ * @Description: 按透明度将文本颜色与背景混合
+ * @param fg
+ * @param bg
+ * @param alpha
+ * @return color.RGBA
+ */
+func blendColor(fg, bg color.RGBA, alpha float64) color.RGBA {
+	mix := func(f, b uint8) uint8 {
+		return uint8(math.Round(float64(f)*alpha + float64(b)*(1-alpha)))
+	}
+	return color.RGBA{R: mix(fg.R, bg.R), G: mix(fg.G, bg.G), B: mix(fg.B, bg.B), A: 0xff}
+}
+
+/**
+ * @Description: WCAG 相对亮度
+ * @param co
+ * @return float64
+ */
+func relativeLuminance(co color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		c := float64(v) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(co.R) + 0.7152*channel(co.G) + 0.0722*channel(co.B)
+}
+
+/**
+ * @Description: WCAG 对比度，范围 1-21
+ * @param l1
+ * @param l2
+ * @return float64
+ */
+func contrastRatio(l1, l2 float64) float64 {
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+/**
+ * @Description: RGB 转 HSL
+ * @param co
+ * @return h	0-1
+ * @return s	0-1
+ * @return l	0-1
+ */
+func rgbToHsl(co color.RGBA) (h, s, l float64) {
+	r := float64(co.R) / 255
+	g := float64(co.G) / 255
+	b := float64(co.B) / 255
+	mx := math.Max(r, math.Max(g, b))
+	mn := math.Min(r, math.Min(g, b))
+	l = (mx + mn) / 2
+	if mx == mn {
+		return 0, 0, l
+	}
+
+	d := mx - mn
+	if l > 0.5 {
+		s = d / (2 - mx - mn)
+	} else {
+		s = d / (mx + mn)
+	}
+	switch mx {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h / 6, s, l
+}
+
+/**
+ * @Description: HSL 转 RGB
+ * @param h
+ * @param s
+ * @param l
+ * @return color.RGBA
+ */
+func hslToRgb(h, s, l float64) color.RGBA {
+	if s == 0 {
+		v := uint8(math.Round(l * 255))
+		return color.RGBA{R: v, G: v, B: v, A: 0xff}
+	}
+
+	hue := func(p, q, t float64) float64 {
+		if t < 0 {
+			t++
+		}
+		if t > 1 {
+			t--
+		}
+		switch {
+		case t < 1.0/6:
+			return p + (q-p)*6*t
+		case t < 1.0/2:
+			return q
+		case t < 2.0/3:
+			return p + (q-p)*(2.0/3-t)*6
+		}
+		return p
+	}
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	return color.RGBA{
+		R: uint8(math.Round(hue(p, q, h+1.0/3) * 255)),
+		G: uint8(math.Round(hue(p, q, h) * 255)),
+		B: uint8(math.Round(hue(p, q, h-1.0/3) * 255)),
+		A: 0xff,
+	}
+}
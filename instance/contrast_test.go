@@ -0,0 +1,68 @@
+package instance
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	black := color.RGBA{A: 0xff}
+	gray := color.RGBA{R: 0x77, G: 0x77, B: 0x77, A: 0xff}
+
+	// WCAG 参考值：黑白 21:1，#777777 与白色约 4.48:1
+	for _, tc := range []struct {
+		a, b color.RGBA
+		want float64
+	}{
+		{white, black, 21},
+		{black, white, 21},
+		{white, white, 1},
+		{gray, white, 4.48},
+	} {
+		got := contrastRatio(relativeLuminance(tc.a), relativeLuminance(tc.b))
+		if math.Abs(got-tc.want) > 0.01 {
+			t.Errorf("contrastRatio(%v, %v) = %.2f, want %.2f", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestPickContrastColor(t *testing.T) {
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	yellow := color.RGBA{R: 0xff, G: 0xff, A: 0xff}
+	navy := color.RGBA{B: 0x80, A: 0xff}
+
+	if got := pickContrastColor([]color.RGBA{yellow, navy}, white, 1, 4.5); got != navy {
+		t.Fatalf("pickContrastColor() = %v, want navy on white", got)
+	}
+
+	// 调色板都不满足时调整亮度
+	light := color.RGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}
+	got := pickContrastColor([]color.RGBA{light}, white, 1, 4.5)
+	if r := contrastRatio(relativeLuminance(got), relativeLuminance(white)); r < 4.5 {
+		t.Fatalf("pickContrastColor() = %v with ratio %.2f, want at least 4.5", got, r)
+	}
+
+	// 按透明度混合后的颜色计算
+	got = pickContrastColor([]color.RGBA{light}, white, 0.8, 4.5)
+	if r := contrastRatio(relativeLuminance(blendColor(got, white, 0.8)), relativeLuminance(white)); r < 4.5 {
+		t.Fatalf("pickContrastColor() with alpha 0.8 = %v with ratio %.2f, want at least 4.5", got, r)
+	}
+	// 无法满足时调整到黑色为止
+	if got := pickContrastColor([]color.RGBA{light}, white, 0.5, 4.5); got != (color.RGBA{A: 0xff}) {
+		t.Fatalf("pickContrastColor() with alpha 0.5 = %v, want black", got)
+	}
+}
+
+func TestTextContrastOption(t *testing.T) {
+	for _, val := range []float64{0.5, 22, -1} {
+		if _, err := NewCaptcha(WithTextContrast(val)); err == nil {
+			t.Errorf("WithTextContrast(%v) did not fail", val)
+		}
+	}
+	cc := newTestCaptcha(t, WithTextContrast(4.5))
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,282 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/hulutech-web/goravel-captcha/assets"
+)
+
+/**
+ * @Description: 字体缺少字符字形时的处理方式
+ */
+const (
+	// 字符只使用包含其字形的字体，没有任何字体包含的字符不会被选中
+	CoverageRestrict = iota
+	// 任意字体缺少任意字符的字形时报错
+	CoverageStrict
+)
+
+// 错误信息中最多列出的字符数量
+const coverageReportLimit = 20
+
+// 覆盖结果缓存的最大数量
+const coverageCacheSize = 32
+
+/**
+ * @Description: 字符集合在字体集合中的字形覆盖情况
+ */
+type glyphCoverage struct {
+	// 每个字符可用的字体，所有字体都包含的字符不记录
+	fonts map[string][]string
+	// 没有任何字体包含的字符
+	missing map[string]bool
+	// 每个字体缺少的字符，按 RangChars 的顺序
+	gaps map[string][]string
+	// 所有字体
+	all []string
+}
+
+var (
+	_coverage   = make(map[uint64]*glyphCoverage)
+	_coverageMu sync.Mutex
+)
+
+/**
+ * @Description: 计算字符集合在字体集合中的字形覆盖情况，结果按字符、字体及资源缓存版本缓存，
+ * 字体被替换后旧版本的结果不会命中，即使计算期间发生替换也不会缓存过期的结果，包含暂存字体时不缓存
+ * @param cfg		字体从配置暂存的资源或资源缓存读取
+ * @param chars
+ * @param fonts
+ * @return *glyphCoverage
+ * @return error	字体无法读取或解析
+ */
+func glyphCoverageOf(cfg *Config, chars, fonts []string) (*glyphCoverage, error) {
+	cacheable := len(cfg.staged) == 0
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprint(assets.Version())))
+	for _, s := range [][]string{chars, fonts} {
+		for _, v := range s {
+			h.Write([]byte(v))
+			h.Write([]byte{0})
+		}
+		h.Write([]byte{1})
+	}
+	key := h.Sum64()
+
+	if cacheable {
+		_coverageMu.Lock()
+		cov, ok := _coverage[key]
+		_coverageMu.Unlock()
+		if ok {
+			return cov, nil
+		}
+	}
+
+	cov := &glyphCoverage{
+		fonts:   make(map[string][]string),
+		missing: make(map[string]bool),
+		gaps:    make(map[string][]string),
+		all:     fonts,
+	}
+	for _, path := range fonts {
+		f, err := cfg.assetFont(path)
+		if err != nil {
+			return nil, fmt.Errorf("CaptchaConfig Error: The [%s] font can not be loaded: %w", path, err)
+		}
+		for _, char := range chars {
+			covered := true
+			for _, r := range char {
+				// 变体选择符及零宽连接符不单独绘制
+				if unicode.In(r, unicode.Variation_Selector, unicode.Join_Control) {
+					continue
+				}
+				if !f.HasGlyph(r) {
+					covered = false
+					break
+				}
+			}
+			if !covered {
+				cov.gaps[path] = append(cov.gaps[path], char)
+			}
+		}
+	}
+
+	if len(cov.gaps) > 0 {
+		for _, char := range chars {
+			var has []string
+			for _, path := range fonts {
+				if !InArrayWithStr(cov.gaps[path], char) {
+					has = append(has, path)
+				}
+			}
+			if len(has) == 0 {
+				cov.missing[char] = true
+			} else if len(has) < len(fonts) {
+				cov.fonts[char] = has
+			}
+		}
+	}
+
+	if !cacheable {
+		return cov, nil
+	}
+	_coverageMu.Lock()
+	if len(_coverage) >= coverageCacheSize {
+		_coverage = make(map[uint64]*glyphCoverage)
+	}
+	_coverage[key] = cov
+	_coverageMu.Unlock()
+	return cov, nil
+}
+
+/**
+ * @Description: 清空覆盖结果缓存，字体文件重新读取后调用
+ */
+func resetGlyphCoverage() {
+	_coverageMu.Lock()
+	_coverage = make(map[uint64]*glyphCoverage)
+	_coverageMu.Unlock()
+}
+
+/**
+ * @Description: 验证字形缺失的处理方式
+ * @param val
+ * @return error
+ */
+func checkCoverageMode(val int) error {
+	if val == CoverageRestrict || val == CoverageStrict {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: GlyphCoverage must be CoverageRestrict or CoverageStrict")
+}
+
+/**
+ * @Description: 检测字形覆盖，严格模式下任意缺失都报错，否则只要求可用的字符足够生成验证码
+ * @param cfg
+ * @return error
+ */
+func checkGlyphCoverage(cfg *Config) error {
+	if len(cfg.RangChars) == 0 || len(cfg.RangFont) == 0 {
+		return nil
+	}
+	cov, err := glyphCoverageOf(cfg, glyphChars(cfg), cfg.RangFont)
+	if err != nil {
+		return err
+	}
+
+	if cfg.GlyphCoverage == CoverageStrict {
+		var errs []error
+		for _, path := range cfg.RangFont {
+			if gaps := cov.gaps[path]; len(gaps) > 0 {
+				errs = append(errs, fmt.Errorf("CaptchaConfig Error: The font [%s] has no glyph for %d chars [%s]", path, len(gaps), reportChars(gaps)))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	if usable := dissimilarCount(cov.usable(cfg.RangChars)); usable < cfg.RangTextLen.Max {
+		var missing []string
+		for _, char := range cfg.RangChars {
+			if cov.missing[char] {
+				missing = append(missing, char)
+			}
+		}
+		return fmt.Errorf("CaptchaConfig Error: Only %d dissimilar char groups have glyphs in RangFont, RangTextLen.Max is %d, missing [%s]", usable, cfg.RangTextLen.Max, reportChars(missing))
+	}
+	if cfg.TextMode == TextModePhrase && randPhrase(cfg, cov) == nil {
+		return fmt.Errorf("CaptchaConfig Error: No phrase has glyphs for all its chars in RangFont")
+	}
+	return nil
+}
+
+/**
+ * @Description: 去除没有任何字体包含的字符
+ * @receiver cov
+ * @param chars
+ * @return []string
+ */
+func (cov *glyphCoverage) usable(chars []string) []string {
+	if len(cov.missing) == 0 {
+		return chars
+	}
+
+	list := make([]string, 0, len(chars))
+	for _, char := range chars {
+		if !cov.missing[char] {
+			list = append(list, char)
+		}
+	}
+	return list
+}
+
+/**
+ * @Description: 包含字符字形的字体
+ * @receiver cov
+ * @param char
+ * @return []string
+ */
+func (cov *glyphCoverage) fontsFor(char string) []string {
+	if fonts, ok := cov.fonts[char]; ok {
+		return fonts
+	}
+	return cov.all
+}
+
+/**
+ * @Description: 列出字符，超出 coverageReportLimit 的部分省略
+ * @param chars
+ * @return string
+ */
+func reportChars(chars []string) string {
+	if len(chars) > coverageReportLimit {
+		return strings.Join(chars[:coverageReportLimit], " ") + " ..."
+	}
+	return strings.Join(chars, " ")
+}
+
+/**
+ * @Description: 复制当前配置后应用字符或字体相关的选项，字形覆盖检测通过后再替换
+ * @receiver cc
+ * @param opt
+ * @return error
+ */
+func (cc *Captcha) applyGlyphs(opt Option) error {
+	return cc.apply(func(c *Config) error {
+		if err := opt(c); err != nil {
+			return err
+		}
+		return checkGlyphCoverage(c)
+	})
+}
+
+// WithGlyphCoverage is a function
+/**
+ * @Description: 字体缺少字符字形时的处理方式，CoverageRestrict 时字符只使用包含其字形的字体，CoverageStrict 时报错
+ * @param val	CoverageRestrict|CoverageStrict
+ * @return Option
+ */
+func WithGlyphCoverage(val int) Option {
+	return func(c *Config) error {
+		if err := checkCoverageMode(val); err != nil {
+			return err
+		}
+		c.GlyphCoverage = val
+		return nil
+	}
+}
+
+// SetGlyphCoverage is a function
+/**
+ * @Description: 设置字体缺少字符字形时的处理方式，切换为严格模式时立即检测当前字符与字体
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetGlyphCoverage(val int) error {
+	return cc.applyGlyphs(WithGlyphCoverage(val))
+}
@@ -0,0 +1,87 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hulutech-web/goravel-captcha/assets"
+)
+
+// 切罗基字母，默认字体中没有该字形
+const missingGlyph = "Ꭰ"
+
+func TestGlyphCoverageStrict(t *testing.T) {
+	chars := append(append([]string(nil), DefaultConfig().RangChars[:20]...), missingGlyph)
+
+	_, err := NewCaptcha(WithRangChars(chars), WithGlyphCoverage(CoverageStrict))
+	if err == nil || !strings.Contains(err.Error(), "has no glyph") || !strings.Contains(err.Error(), missingGlyph) {
+		t.Fatalf("NewCaptcha() = %v, want a missing glyph error for [%s]", err, missingGlyph)
+	}
+
+	cc := newTestCaptcha(t, WithRangChars(chars))
+	if err := cc.SetGlyphCoverage(CoverageStrict); err == nil || !strings.Contains(err.Error(), missingGlyph) {
+		t.Fatalf("SetGlyphCoverage() = %v, want a missing glyph error for [%s]", err, missingGlyph)
+	}
+	if cfg := cc.Config(); cfg.GlyphCoverage != CoverageRestrict {
+		t.Fatal("rejected SetGlyphCoverage() replaced the config")
+	}
+}
+
+func TestGlyphCoverageRestrict(t *testing.T) {
+	chars := append(append([]string(nil), DefaultConfig().RangChars[:20]...), missingGlyph)
+	cc := newTestCaptcha(t, WithRangChars(chars))
+	cfg := cc.Config()
+
+	cov, err := glyphCoverageOf(&cfg, chars, cfg.RangFont)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cov.missing[missingGlyph] {
+		t.Fatalf("[%s] is not reported as missing", missingGlyph)
+	}
+	usable := cov.usable(chars)
+	if len(usable) != len(chars)-1 {
+		t.Fatalf("usable() = %v, want all chars except [%s]", usable, missingGlyph)
+	}
+	for _, char := range usable {
+		if char == missingGlyph {
+			t.Fatalf("usable() kept [%s]", missingGlyph)
+		}
+	}
+
+	dots, _, _, _, err := cc.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dot := range dots {
+		if dot.Text == missingGlyph {
+			t.Fatalf("Generate() used [%s] without a glyph", missingGlyph)
+		}
+	}
+}
+
+func TestGlyphCoverageReplacedFont(t *testing.T) {
+	content, err := assets.GetAssetCache(DefaultConfig().RangFont[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "test/coverage.ttf"
+	setAssetCache(path, content, true)
+	t.Cleanup(func() { clearAssetCache([]string{path}) })
+
+	cfg := DefaultConfig()
+	chars := []string{"A", "B"}
+	a, err := glyphCoverageOf(&cfg, chars, []string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := glyphCoverageOf(&cfg, chars, []string{path}); b != a {
+		t.Fatal("glyphCoverageOf() computed the same coverage again")
+	}
+
+	// 直接替换资源缓存，不经过 setAssetCache 的清理，旧结果也不能命中
+	assets.SetAssetCache(path, []byte("not a font"), true)
+	if _, err := glyphCoverageOf(&cfg, chars, []string{path}); err == nil {
+		t.Fatal("glyphCoverageOf() returned the coverage of the replaced font")
+	}
+}
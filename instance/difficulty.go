@@ -0,0 +1,309 @@
+package instance
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCheckPadding 校验点区域默认扩充的像素
+const DefaultCheckPadding = 5
+
+// 失败记录在缓存目录下的子目录，不参与验证码缓存的超时清理
+const failureDir = "failures"
+
+// FailureHistory is a type
+/**
+ * @Description: 同一来源（IP 或会话）的失败记录
+ */
+type FailureHistory struct {
+	// 按半衰期衰减后的失败分数，每次失败加1
+	Score float64 `json:"score"`
+	// 累计失败次数
+	Failures int `json:"failures"`
+	// 最后一次失败时间
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// FailureTracker is a type
+/**
+ * @Description: 失败记录，与验证码缓存共用目录，分数随时间按半衰期衰减
+ */
+type FailureTracker struct {
+	halfLife time.Duration
+	mu       sync.Mutex
+}
+
+// NewFailureTracker is a function
+/**
+ * @Description: 创建失败记录
+ * @param halfLife	半衰期，失败分数每经过一个半衰期减半，小于等于0时为10分钟
+ * @return *FailureTracker
+ */
+func NewFailureTracker(halfLife time.Duration) *FailureTracker {
+	if halfLife <= 0 {
+		halfLife = time.Minute * 10
+	}
+	return &FailureTracker{halfLife: halfLife}
+}
+
+// History is a function
+/**
+ * @Description: 获取衰减后的失败记录，分数衰减到可忽略时删除记录
+ * @receiver t
+ * @param identity
+ * @return FailureHistory
+ */
+func (t *FailureTracker) History(identity string) FailureHistory {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.load(identity, time.Now())
+}
+
+// Fail is a function
+/**
+ * @Description: 记录一次失败
+ * @receiver t
+ * @param identity
+ * @return FailureHistory	记录后的失败记录
+ */
+func (t *FailureTracker) Fail(identity string) FailureHistory {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	h := t.load(identity, now)
+	h.Score++
+	h.Failures++
+	h.UpdatedAt = now.Unix()
+
+	bt, _ := json.Marshal(h)
+	if err := os.MkdirAll(t.dir(), 0770); err == nil {
+		_ = os.WriteFile(t.file(identity), bt, 0644)
+	}
+	return h
+}
+
+// Reset is a function
+/**
+ * @Description: 清除失败记录
+ * @receiver t
+ * @param identity
+ */
+func (t *FailureTracker) Reset(identity string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_ = os.Remove(t.file(identity))
+}
+
+/**
+ * @Description: 读取失败记录并按经过的时间衰减
+ * @receiver t
+ * @param identity
+ * @param now
+ * @return FailureHistory
+ */
+func (t *FailureTracker) load(identity string, now time.Time) FailureHistory {
+	var h FailureHistory
+	bt, err := os.ReadFile(t.file(identity))
+	if err != nil || json.Unmarshal(bt, &h) != nil {
+		return FailureHistory{}
+	}
+
+	elapsed := now.Sub(time.Unix(h.UpdatedAt, 0))
+	if elapsed > 0 {
+		h.Score *= math.Pow(0.5, float64(elapsed)/float64(t.halfLife))
+	}
+	if h.Score < 0.1 {
+		_ = os.Remove(t.file(identity))
+		return FailureHistory{}
+	}
+	return h
+}
+
+func (t *FailureTracker) dir() string {
+	return getCacheDir() + failureDir + "/"
+}
+
+func (t *FailureTracker) file(identity string) string {
+	return t.dir() + Md5ToString(identity) + ".json"
+}
+
+// Difficulty is a type
+/**
+ * @Description: 生成验证码使用的难度，包括配置、校验点扩充像素和有效期
+ */
+type Difficulty struct {
+	// 生成配置
+	Config Config
+	// 校验点区域扩充的像素
+	Padding int
+	// 验证码有效期
+	TTL time.Duration
+}
+
+// DifficultyPolicy is a type
+/**
+ * @Description: 根据失败记录调整难度，返回的配置不合法时使用原难度
+ */
+type DifficultyPolicy func(base Difficulty, history FailureHistory) Difficulty
+
+// DefaultDifficultyPolicy is a function
+/**
+ * @Description: 默认难度策略，失败分数四舍五入为等级，最多三级：随机字符串长度及验证文本数量随等级增加、加强扭曲、缩小校验区域并缩短有效期。
+ * 增加后的长度受主图尺寸、缩略图宽度、字符集合等限制无法通过校验时逐级回退，长度的提升到此为止，扭曲、校验区域及有效期仍按等级调整
+ * @param base
+ * @param history
+ * @return Difficulty
+ */
+func DefaultDifficultyPolicy(base Difficulty, history FailureHistory) Difficulty {
+	level := min(int(math.Round(history.Score)), 3)
+	if level <= 0 {
+		return base
+	}
+
+	d := base
+	d.Padding = max(d.Padding-level*2, 0)
+	d.TTL = max(d.TTL>>level, time.Second*30)
+	for step := level; step >= 0; step-- {
+		c := base.Config.Clone()
+		c.ImageFontDistort = min(c.ImageFontDistort+level, DistortLevel5)
+		c.RangTextLen = RangeVal{c.RangTextLen.Min + step, c.RangTextLen.Max + step}
+		// 验证文本数量不能超出随机字符串的最小长度
+		checkMax := min(c.RangCheckTextLen.Max+step, c.RangTextLen.Min)
+		checkMin := min(c.RangCheckTextLen.Min+step, checkMax)
+		c.RangCheckTextLen = RangeVal{checkMin, checkMax}
+		d.Config = c
+		if step == 0 || c.Validate() == nil {
+			break
+		}
+	}
+	return d
+}
+
+var (
+	_tracker    *FailureTracker
+	_policy     DifficultyPolicy
+	_adaptiveMu sync.RWMutex
+)
+
+// EnableAdaptive is a function
+/**
+ * @Description: 启用自适应难度，policy 为 nil 时使用 DefaultDifficultyPolicy
+ * @param tracker
+ * @param policy
+ */
+func EnableAdaptive(tracker *FailureTracker, policy DifficultyPolicy) {
+	if policy == nil {
+		policy = DefaultDifficultyPolicy
+	}
+
+	_adaptiveMu.Lock()
+	defer _adaptiveMu.Unlock()
+	_tracker = tracker
+	_policy = policy
+}
+
+/**
+ * @Description: 获取自适应难度设置，未启用时 tracker 为 nil
+ * @return *FailureTracker
+ * @return DifficultyPolicy
+ */
+func adaptive() (*FailureTracker, DifficultyPolicy) {
+	_adaptiveMu.RLock()
+	defer _adaptiveMu.RUnlock()
+
+	return _tracker, _policy
+}
+
+// MakeAdaptiveCaptcha is a function
+/**
+ * @Description: 使用命名配置生成验证码，启用自适应难度时按来源的失败记录提高难度
+ * @param name
+ * @param identity	来源标识，IP 或会话ID
+ * @return interface{}	位置信息
+ * @return string		主图Base64
+ * @return string		缩略图Base64
+ * @return string		验证码KEY
+ * @return error
+ */
+func MakeAdaptiveCaptcha(name, identity string) (interface{}, string, string, string, error) {
+	return makeCaptcha(name, identity, "")
+}
+
+/**
+ * @Description: 使用命名配置生成验证码，按来源的失败记录调整难度，并覆盖配色
+ * @param name
+ * @param identity	来源标识，为空时不调整难度
+ * @param theme		配色名称，为空时使用实例配色
+ * @return interface{}
+ * @return string
+ * @return string
+ * @return string
+ * @return error
+ */
+func makeCaptcha(name, identity, theme string) (interface{}, string, string, string, error) {
+	p, ok := GetProfile(name)
+	if !ok {
+		return MakeCaptchaWithProfile(name)
+	}
+
+	base := Difficulty{Config: p.Captcha.Config(), Padding: DefaultCheckPadding, TTL: p.TTL}
+	d := base
+	if tracker, policy := adaptive(); tracker != nil && identity != "" {
+		d = policy(base, tracker.History(identity))
+		if err := d.Config.Validate(); err != nil {
+			d = base
+		}
+	}
+	if theme != "" {
+		if err := WithTheme(theme)(&d.Config); err != nil {
+			return "", "", "", "", err
+		}
+	}
+	// 难度及配色未变化时使用实例配置，可以从预渲染池中取出
+	if d.Config.Equal(base.Config) && d.Padding == base.Padding && d.TTL == base.TTL {
+		return MakeCaptchaWithProfile(name)
+	}
+
+	dots, b64, tb64, key, err := p.Captcha.GenerateWithConfig(d.Config)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	//加入缓存
+	writeCache(cacheRecord{
+		Profile:   p.Name,
+		ExpiresAt: time.Now().Add(d.TTL).Unix(),
+		Padding:   d.Padding,
+		Dots:      dots,
+	}, key)
+	return dots, b64, tb64, key, nil
+}
+
+// VerifyAdaptiveCaptcha is a function
+/**
+ * @Description: 校验验证码，启用自适应难度时记录失败，校验通过后清除来源的失败记录，下一个验证码恢复默认难度
+ * @param name
+ * @param identity	来源标识，IP 或会话ID
+ * @param key
+ * @param dots
+ * @return bool
+ */
+func VerifyAdaptiveCaptcha(name, identity, key, dots string) bool {
+	checked := VerifyCaptchaWithProfile(name, key, dots)
+
+	tracker, _ := adaptive()
+	if tracker == nil || identity == "" {
+		return checked
+	}
+	if checked {
+		tracker.Reset(identity)
+	} else {
+		tracker.Fail(identity)
+	}
+	return checked
+}
@@ -0,0 +1,181 @@
+package instance
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// 写入指定时间的失败记录，模拟经过的时间
+func writeFailure(t *testing.T, tracker *FailureTracker, identity string, h FailureHistory) {
+	t.Helper()
+	bt, _ := json.Marshal(h)
+	if err := os.MkdirAll(tracker.dir(), 0770); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tracker.file(identity), bt, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailureTrackerEscalate(t *testing.T) {
+	tracker := NewFailureTracker(time.Hour)
+	t.Cleanup(func() { tracker.Reset("escalate") })
+
+	for i := 1; i <= 3; i++ {
+		h := tracker.Fail("escalate")
+		if h.Failures != i || math.Abs(h.Score-float64(i)) > 0.01 {
+			t.Fatalf("Fail() #%d = %+v", i, h)
+		}
+	}
+	if h := tracker.History("escalate"); h.Failures != 3 {
+		t.Fatalf("History() = %+v, want 3 failures", h)
+	}
+	tracker.Reset("escalate")
+	if h := tracker.History("escalate"); h != (FailureHistory{}) {
+		t.Fatalf("History() after Reset() = %+v", h)
+	}
+}
+
+func TestFailureTrackerDecay(t *testing.T) {
+	tracker := NewFailureTracker(10 * time.Minute)
+	t.Cleanup(func() { tracker.Reset("decay") })
+
+	// 经过两个半衰期，分数为四分之一
+	writeFailure(t, tracker, "decay", FailureHistory{Score: 4, Failures: 4, UpdatedAt: time.Now().Add(-20 * time.Minute).Unix()})
+	if h := tracker.History("decay"); h.Failures != 4 || math.Abs(h.Score-1) > 0.01 {
+		t.Fatalf("History() = %+v, want a score of 1", h)
+	}
+
+	// 衰减到可忽略时删除记录
+	writeFailure(t, tracker, "decay", FailureHistory{Score: 1, Failures: 1, UpdatedAt: time.Now().Add(-time.Hour).Unix()})
+	if h := tracker.History("decay"); h != (FailureHistory{}) {
+		t.Fatalf("History() = %+v, want an empty history", h)
+	}
+	if _, err := os.Stat(tracker.file("decay")); !os.IsNotExist(err) {
+		t.Fatalf("decayed failure record still exists: %v", err)
+	}
+}
+
+func TestDefaultDifficultyPolicy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RangTextLen = RangeVal{5, 6}
+	cfg.RangCheckTextLen = RangeVal{2, 3}
+	cfg.ImageFontDistort = DistortNone
+	base := Difficulty{Config: cfg, Padding: DefaultCheckPadding, TTL: DefaultTTL}
+
+	for _, tc := range []struct {
+		score   float64
+		text    RangeVal
+		check   RangeVal
+		distort int
+		padding int
+		ttl     time.Duration
+	}{
+		{0.4, RangeVal{5, 6}, RangeVal{2, 3}, DistortNone, 5, DefaultTTL},
+		{1, RangeVal{6, 7}, RangeVal{3, 4}, DistortLevel1, 3, DefaultTTL / 2},
+		{2, RangeVal{7, 8}, RangeVal{4, 5}, DistortLevel2, 1, DefaultTTL / 4},
+		// 最多三级
+		{3, RangeVal{8, 9}, RangeVal{5, 6}, DistortLevel3, 0, DefaultTTL / 8},
+		{10, RangeVal{8, 9}, RangeVal{5, 6}, DistortLevel3, 0, DefaultTTL / 8},
+	} {
+		d := DefaultDifficultyPolicy(base, FailureHistory{Score: tc.score})
+		if d.Config.RangTextLen != tc.text || d.Config.RangCheckTextLen != tc.check || d.Config.ImageFontDistort != tc.distort || d.Padding != tc.padding || d.TTL != tc.ttl {
+			t.Errorf("score %v: text %v, check %v, distort %d, padding %d, ttl %v", tc.score, d.Config.RangTextLen, d.Config.RangCheckTextLen, d.Config.ImageFontDistort, d.Padding, d.TTL)
+		}
+		if err := d.Config.Validate(); err != nil {
+			t.Errorf("score %v: %v", tc.score, err)
+		}
+	}
+	if base.Config.RangTextLen != (RangeVal{5, 6}) || base.Config.RangCheckTextLen != (RangeVal{2, 3}) || base.Config.ImageFontDistort != DistortNone {
+		t.Fatal("DefaultDifficultyPolicy() modified the base config")
+	}
+
+	// 有效期不短于30秒
+	base.TTL = time.Minute
+	if d := DefaultDifficultyPolicy(base, FailureHistory{Score: 3}); d.TTL != 30*time.Second {
+		t.Fatalf("TTL = %v, want 30s", d.TTL)
+	}
+}
+
+func TestDefaultDifficultyPolicyCapped(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RangTextLen = RangeVal{5, 6}
+	cfg.RangCheckTextLen = RangeVal{2, 3}
+	// 缩略图最多放下 4 个验证文本
+	cfg.ThumbnailSize.Width = 4 * cfg.RangCheckFontSize.Min
+	base := Difficulty{Config: cfg, Padding: DefaultCheckPadding, TTL: DefaultTTL}
+
+	// 长度回退到能通过校验的等级，其余调整仍按三级
+	d := DefaultDifficultyPolicy(base, FailureHistory{Score: 3})
+	if d.Config.RangTextLen != (RangeVal{6, 7}) || d.Config.RangCheckTextLen != (RangeVal{3, 4}) {
+		t.Errorf("text %v, check %v; want {6 7} and {3 4}", d.Config.RangTextLen, d.Config.RangCheckTextLen)
+	}
+	if d.Config.ImageFontDistort != DistortLevel3 || d.Padding != 0 || d.TTL != DefaultTTL/8 {
+		t.Errorf("distort %d, padding %d, ttl %v", d.Config.ImageFontDistort, d.Padding, d.TTL)
+	}
+	if err := d.Config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultRouteEscalates(t *testing.T) {
+	tracker := NewFailureTracker(time.Hour)
+	t.Cleanup(func() { tracker.Reset("route") })
+	base := Difficulty{Config: InitCaptcha().Config(), Padding: DefaultCheckPadding, TTL: DefaultTTL}
+
+	prev := base
+	for i := 1; i <= 3; i++ {
+		d := DefaultDifficultyPolicy(base, tracker.Fail("route"))
+		if err := d.Config.Validate(); err != nil {
+			t.Fatalf("failure %d: %v", i, err)
+		}
+		// 每次失败后四项都提高难度
+		if d.Config.RangCheckTextLen.Min <= prev.Config.RangCheckTextLen.Min ||
+			d.Config.ImageFontDistort <= prev.Config.ImageFontDistort ||
+			d.Padding >= prev.Padding && prev.Padding > 0 ||
+			d.TTL >= prev.TTL {
+			t.Fatalf("failure %d: check %v, distort %d, padding %d, ttl %v did not escalate from check %v, distort %d, padding %d, ttl %v",
+				i, d.Config.RangCheckTextLen, d.Config.ImageFontDistort, d.Padding, d.TTL,
+				prev.Config.RangCheckTextLen, prev.Config.ImageFontDistort, prev.Padding, prev.TTL)
+		}
+		prev = d
+	}
+}
+
+func TestVerifyAdaptiveCaptchaResets(t *testing.T) {
+	tracker := NewFailureTracker(time.Hour)
+	EnableAdaptive(tracker, nil)
+	t.Cleanup(func() {
+		tracker.Reset("verify")
+		_adaptiveMu.Lock()
+		_tracker, _policy = nil, nil
+		_adaptiveMu.Unlock()
+	})
+	registerTestProfile(t, "test-adaptive", newTestCaptcha(t))
+
+	_, _, _, key, err := MakeAdaptiveCaptcha("test-adaptive", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyAdaptiveCaptcha("test-adaptive", "verify", key, "0,0") {
+		t.Fatal("VerifyAdaptiveCaptcha() accepted wrong dots")
+	}
+	if h := tracker.History("verify"); h.Failures != 1 {
+		t.Fatalf("History() = %+v, want 1 failure", h)
+	}
+
+	// 校验通过后清除失败记录
+	dots, _, _, key, err := MakeAdaptiveCaptcha("test-adaptive", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAdaptiveCaptcha("test-adaptive", "verify", key, clickDots(dots.(map[int]CharDot))) {
+		t.Fatal("VerifyAdaptiveCaptcha() rejected the issued dots")
+	}
+	if h := tracker.History("verify"); h != (FailureHistory{}) {
+		t.Fatalf("History() after a success = %+v, want an empty history", h)
+	}
+}
@@ -3,8 +3,10 @@ package instance
 import (
 	"fmt"
 	"github.com/golang/freetype"
+	"github.com/hulutech-web/goravel-captcha/assets"
 	"golang.org/x/image/draw"
 	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
 	"image"
 	"image/color"
 	"math"
@@ -24,6 +26,7 @@ type DrawDot struct {
 	Color   string
 	Color2  string
 	Font    string
+	Icon    string
 }
 
 // DrawCanvas is a type
@@ -36,6 +39,12 @@ type DrawCanvas struct {
 	Height int
 	// 背景图片
 	Background string
+	// 已读取或生成的背景图，不为空时不再读取 Background
+	BackgroundImage image.Image
+	// 背景图裁剪位置，为空时随机裁剪
+	BackgroundCut *image.Point
+	// 调色板画布的底色，为空时透明
+	BackgroundColor string
 	// 缩略图扭曲程度，值为 Distort...,
 	BackgroundDistort int
 	// 缩略图小圆点数量
@@ -54,6 +63,8 @@ type DrawCanvas struct {
 	TextShadowColor string
 	// 文本阴影偏移位置
 	TextShadowPoint Point
+	// 主图干扰层
+	Interference Interference
 }
 
 // AreaPoint is a type
@@ -104,14 +115,10 @@ func (cd *Draw) CreateCanvas(params DrawCanvas, isAlpha bool) (img *image.NRGBA)
 	width := params.Width
 	height := params.Height
 	img = image.NewNRGBA(image.Rect(0, 0, width, height))
-	// 画背景
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			if isAlpha {
-				img.Set(x, y, color.Alpha{A: uint8(0)})
-			} else {
-				img.Set(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
-			}
+	// 画背景，NewNRGBA 已是全透明
+	if !isAlpha {
+		for i := range img.Pix {
+			img.Pix[i] = 255
 		}
 	}
 	return
@@ -137,18 +144,7 @@ func (cd *Draw) Draw(params DrawCanvas) (image.Image, error) {
 		maxY := areaPoint.MaxY
 		width := maxX - minX
 		height := maxY - minY
-		nW := textImg.Bounds().Max.X
-		nH := textImg.Bounds().Max.Y
-		for x := 0; x < nW; x++ {
-			for y := 0; y < nH; y++ {
-				co := textImg.At(x, y)
-				if _, _, _, a := co.RGBA(); a > 0 {
-					if x >= minX && x <= maxX && y >= minY && y <= maxY {
-						canvas.Set(dot.Dx+(x-minX), dot.Dy-height+(y-minY), textImg.At(x, y))
-					}
-				}
-			}
-		}
+		cd.compositeText(canvas, textImg, areaPoint, dot.Dx, dot.Dy-height)
 		// 重置尺寸
 		dot.Height = height
 		dot.Width = width
@@ -157,23 +153,28 @@ func (cd *Draw) Draw(params DrawCanvas) (image.Image, error) {
 		dot.Dy = maxY
 	}
 
-	bgFile := params.Background
-	imgBg, iErr := getAssetCache(bgFile)
-	if iErr != nil {
-		return canvas, iErr
-	}
-
-	img, dErr := decodingBinaryToImage(imgBg)
-	if dErr != nil {
-		return canvas, dErr
+	img := params.BackgroundImage
+	if img == nil {
+		var iErr error
+		img, iErr = getAssetImage(params.Background)
+		if iErr != nil {
+			return canvas, iErr
+		}
 	}
 
 	b := canvas.Bounds()
-	m := image.NewNRGBA(b)
-	curX, curY := cd.rangCutImage(params.Width, params.Height, img)
-	draw.Draw(m, b, img, image.Point{X: curX, Y: curY}, draw.Src)
+	// 使用 RGBA 画布，背景及文本合成和 JPEG 编码都可走标准库的快速路径
+	m := image.NewRGBA(b)
+	var cut image.Point
+	if params.BackgroundCut != nil {
+		cut = *params.BackgroundCut
+	} else {
+		cut.X, cut.Y = cd.rangCutImage(params.Width, params.Height, img)
+	}
+	draw.Draw(m, b, img, cut, draw.Src)
 	draw.Draw(m, canvas.Bounds(), canvas, image.Point{}, draw.Over)
-	subImg := m.SubImage(image.Rect(0, 0, params.Width, params.Height)).(*image.NRGBA)
+	cd.drawInterference(m, params)
+	subImg := m.SubImage(image.Rect(0, 0, params.Width, params.Height)).(*image.RGBA)
 	return subImg, nil
 }
 
@@ -192,6 +193,10 @@ func (cd *Draw) DrawWithPalette(params DrawCanvas, colorA []color.Color, colorB
 	p := []color.Color{
 		color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x00},
 	}
+	// 调色板第一个颜色即画布底色
+	if params.BackgroundColor != "" {
+		p[0], _ = ParseHexColor(params.BackgroundColor)
+	}
 	for ak := range colorA {
 		p = append(p, colorA[ak])
 	}
@@ -209,34 +214,29 @@ func (cd *Draw) DrawWithPalette(params DrawCanvas, colorA []color.Color, colorB
 	}
 
 	for _, dot := range dots {
-		// 读字体数据
-		fontBytes, err := getAssetCache(dot.Font)
-		if err != nil {
-			return canvas, err
+		// 画图标
+		if dot.Icon != "" {
+			hexColor, _ := ParseHexColor(dot.Color)
+			r := image.Rect(dot.Dx, dot.Dy-dot.Height, dot.Dx+dot.Width, dot.Dy)
+			if err := cd.drawIcon(canvas, dot.Icon, image.NewUniform(hexColor), r); err != nil {
+				return canvas, err
+			}
+			continue
 		}
-		fontN, err := freetype.ParseFont(fontBytes)
+
+		// 读字体数据
+		fontN, err := getAssetFont(dot.Font)
 		if err != nil {
 			return canvas, err
 		}
 
-		dc := freetype.NewContext()
-		dc.SetDPI(float64(dot.FontDPI))
-		dc.SetFont(fontN)
-		dc.SetClip(canvas.Bounds())
-		dc.SetDst(canvas)
-
-		// 文字大小
-		dc.SetFontSize(float64(dot.Size))
-
 		// 文字颜色
 		hexColor, _ := ParseHexColor(dot.Color)
 		fontColor := image.NewUniform(hexColor)
-		dc.SetSrc(fontColor)
 
 		// 画文本
-		text := fmt.Sprintf("%s", dot.Text)
 		pt := freetype.Pt(dot.Dx, dot.Dy) // 字出现的位置
-		_, err = dc.DrawString(text, pt)
+		err = cd.drawString(canvas, fontN, fontColor, dot, font.HintingNone, pt)
 		if err != nil {
 			return canvas, err
 		}
@@ -244,14 +244,10 @@ func (cd *Draw) DrawWithPalette(params DrawCanvas, colorA []color.Color, colorB
 
 	if params.Background != "" {
 		bgFile := params.Background
-		imgBg, iErr := getAssetCache(bgFile)
+		img, iErr := getAssetImage(bgFile)
 		if iErr != nil {
 			return canvas, iErr
 		}
-		img, dErr := decodingBinaryToImage(imgBg)
-		if dErr != nil {
-			return canvas, dErr
-		}
 
 		b := img.Bounds()
 		m := image.NewNRGBA(b)
@@ -360,9 +356,9 @@ func (cd *Draw) DrawTextImg(dot DrawDot, params DrawCanvas) (*Palette, *AreaPoin
 		shadowImg := cd.DrawStrImg(dot, shadowColorArr, shadowColor)
 		pointX := params.TextShadowPoint.X
 		pointY := params.TextShadowPoint.Y
-		draw.Draw(canvas, shadowImg.Bounds(), shadowImg, image.Point{X: pointX, Y: pointY}, draw.Over)
+		cd.drawPaletteOver(canvas, shadowImg, image.Point{X: pointX, Y: pointY})
 	}
-	draw.Draw(canvas, textImg.Bounds(), textImg, image.Point{}, draw.Over)
+	cd.drawPaletteOver(canvas, textImg, image.Point{})
 
 	// 旋转效果
 	canvas.Rotate(dot.Angle)
@@ -394,39 +390,31 @@ func (cd *Draw) DrawStrImg(dot DrawDot, colorArr []color.RGBA, fc color.Color) *
 		Height: dot.Height + 10,
 	}, colorArr)
 
-	// 读字体数据
-	fontBytes, err := getAssetCache(dot.Font)
-	if err != nil {
+	// 图标缩放到文本区域
+	if dot.Icon != "" {
+		_ = cd.drawIcon(canvas, dot.Icon, image.NewUniform(fc), image.Rect(5, 5, 5+dot.Width, 5+dot.Height))
 		return canvas
 	}
-	fontN, err := freetype.ParseFont(fontBytes)
+
+	// 读字体数据
+	fontN, err := getAssetFont(dot.Font)
 	if err != nil {
 		return canvas
 	}
 
-	dc := freetype.NewContext()
-	dc.SetDPI(float64(dot.FontDPI))
-	dc.SetFont(fontN)
-	dc.SetClip(canvas.Bounds())
-	dc.SetDst(canvas)
-
-	// 文字大小
-	dc.SetFontSize(float64(dot.Size))
-	dc.SetHinting(font.HintingFull)
-
 	// 文字颜色
 	fontColor := image.NewUniform(fc)
-	dc.SetSrc(fontColor)
 
 	// 画文本
 	text := fmt.Sprintf("%s", dot.Text)
 
-	pt := freetype.Pt(12, dot.Height-5) // 字出现的位置
-	if IsChineseChar(text) {
-		pt = freetype.Pt(10, dot.Height) // 字出现的位置
+	// 文本区域在画布中居中，基线位于区域顶部向下 Ascent 处
+	pt := freetype.Pt(5, dot.Height+5)
+	if tm, err := measureFont(fontN, text, dot.Size, dot.FontDPI); err == nil {
+		pt = freetype.Pt(5, 5+(dot.Height-tm.Height())/2+tm.Ascent)
 	}
 
-	_, err = dc.DrawString(text, pt)
+	err = cd.drawString(canvas, fontN, fontColor, dot, font.HintingFull, pt)
 	if err != nil {
 		return nil
 	}
@@ -434,6 +422,43 @@ func (cd *Draw) DrawStrImg(dot DrawDot, colorArr []color.RGBA, fc color.Color) *
 	return canvas
 }
 
+/**
+ * @Description: 绘制文本，TrueType 字体使用 freetype 绘制，OTF 及字体集合中的字体使用 opentype 绘制
+ * @receiver cd
+ * @param dst
+ * @param f
+ * @param src		文字颜色
+ * @param dot
+ * @param hinting
+ * @param pt		基线起点
+ * @return error
+ */
+func (cd *Draw) drawString(dst draw.Image, f *assets.Font, src image.Image, dot DrawDot, hinting font.Hinting, pt fixed.Point26_6) error {
+	text := fmt.Sprintf("%s", dot.Text)
+	if f.TrueType != nil {
+		dc := freetype.NewContext()
+		dc.SetDPI(float64(dot.FontDPI))
+		dc.SetFont(f.TrueType)
+		dc.SetClip(dst.Bounds())
+		dc.SetDst(dst)
+		dc.SetFontSize(float64(dot.Size))
+		dc.SetHinting(hinting)
+		dc.SetSrc(src)
+		_, err := dc.DrawString(text, pt)
+		return err
+	}
+
+	face, err := f.NewFace(float64(dot.Size), float64(dot.FontDPI), hinting)
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	d := font.Drawer{Dst: dst, Src: src, Face: face, Dot: pt}
+	d.DrawString(text)
+	return nil
+}
+
 /**
  * @Description: 计算剪裁空白多余空白
  * @receiver cd
@@ -441,30 +466,31 @@ func (cd *Draw) DrawStrImg(dot DrawDot, colorArr []color.RGBA, fc color.Color) *
  * @return *AreaPoint
  */
 func (cd *Draw) calcImageSpace(pa *Palette) *AreaPoint {
-	nW := pa.Bounds().Max.X
-	nH := pa.Bounds().Max.Y
+	nW := pa.Rect.Dx()
+	nH := pa.Rect.Dy()
+	opaque := pa.opaqueTable()
 	// 计算裁剪的最小及最大的坐标
 	minX := nW
 	maxX := 0
 	minY := nH
 	maxY := 0
-	for x := 0; x < nW; x++ {
-		for y := 0; y < nH; y++ {
-			co := pa.At(x, y)
-			if _, _, _, a := co.RGBA(); a > 0 {
-				if x < minX {
-					minX = x
-				}
-				if x > maxX {
-					maxX = x
-				}
-
-				if y < minY {
-					minY = y
-				}
-				if y > maxY {
-					maxY = y
-				}
+	for y := 0; y < nH; y++ {
+		row := pa.Pix[y*pa.Stride : y*pa.Stride+nW]
+		for x, idx := range row {
+			if !opaque[idx] {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
 			}
 		}
 	}
@@ -482,6 +508,95 @@ func (cd *Draw) calcImageSpace(pa *Palette) *AreaPoint {
 	}
 }
 
+/**
+ * @Description: 将文本图片裁剪区域内的不透明像素直接写入画布像素
+ * @receiver cd
+ * @param canvas
+ * @param textImg
+ * @param ap		文本图片的裁剪区域
+ * @param dx		写入画布的起始x
+ * @param dy		写入画布的起始y
+ */
+func (cd *Draw) compositeText(canvas *image.NRGBA, textImg *Palette, ap *AreaPoint, dx, dy int) {
+	// 预先转换调色板颜色，避免逐像素的接口调用
+	var colors [256]color.NRGBA
+	opaque := textImg.opaqueTable()
+	for i, c := range textImg.Palette {
+		if i >= len(colors) {
+			break
+		}
+		colors[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+	}
+
+	nW := textImg.Rect.Dx()
+	nH := textImg.Rect.Dy()
+	bounds := canvas.Bounds()
+	for y := ap.MinY; y <= ap.MaxY && y < nH; y++ {
+		cy := dy + (y - ap.MinY)
+		if cy < bounds.Min.Y || cy >= bounds.Max.Y {
+			continue
+		}
+		row := textImg.Pix[y*textImg.Stride : y*textImg.Stride+nW]
+		for x := ap.MinX; x <= ap.MaxX && x < nW; x++ {
+			idx := row[x]
+			if !opaque[idx] {
+				continue
+			}
+			cx := dx + (x - ap.MinX)
+			if cx < bounds.Min.X || cx >= bounds.Max.X {
+				continue
+			}
+			c := colors[idx]
+			i := canvas.PixOffset(cx, cy)
+			canvas.Pix[i+0] = c.R
+			canvas.Pix[i+1] = c.G
+			canvas.Pix[i+2] = c.B
+			canvas.Pix[i+3] = c.A
+		}
+	}
+}
+
+/**
+ * @Description: 将调色板图片的不透明像素按索引映射覆盖到目标调色板，等同于 draw.Draw(dst, src.Bounds(), src, sp, draw.Over)
+ * @receiver cd
+ * @param dst
+ * @param src
+ * @param sp
+ */
+func (cd *Draw) drawPaletteOver(dst *Palette, src *Palette, sp image.Point) {
+	// 源索引到目标索引的映射
+	var mapping [256]uint8
+	opaque := src.opaqueTable()
+	for i, c := range src.Palette {
+		if i >= len(mapping) {
+			break
+		}
+		mapping[i] = uint8(dst.Palette.Index(c))
+	}
+
+	dW := dst.Rect.Dx()
+	dH := dst.Rect.Dy()
+	sW := src.Rect.Dx()
+	sH := src.Rect.Dy()
+	for y := 0; y < sH && y < dH; y++ {
+		sy := y + sp.Y
+		if sy < 0 || sy >= sH {
+			continue
+		}
+		srcRow := src.Pix[sy*src.Stride : sy*src.Stride+sW]
+		dstRow := dst.Pix[y*dst.Stride : y*dst.Stride+dW]
+		for x := 0; x < sW && x < dW; x++ {
+			sx := x + sp.X
+			if sx < 0 || sx >= sW {
+				continue
+			}
+			if idx := srcRow[sx]; opaque[idx] {
+				dstRow[x] = mapping[idx]
+			}
+		}
+	}
+}
+
 /**
  * @Description: 格式透明度
  * @receiver cd
@@ -511,29 +626,6 @@ func (cd *Draw) centerWithImage(m image.Image) image.Image {
 	return centerImage
 }
 
-/**
- * @Description: 画中心线
- * @receiver cd
- * @param m
- * @param dotSize
- */
-func (cd *Draw) strikeThrough(m *Palette, dotSize int) {
-	maxx := m.Bounds().Max.X
-	maxy := m.Bounds().Max.Y
-	y := RandInt(maxy/3, maxy-maxy/3)
-	amplitude := RandFloat(5, 20)
-	period := RandFloat(80, 180)
-	dx := 2.0 * math.Pi / period
-	for x := 0; x < maxx; x++ {
-		xo := amplitude * math.Cos(float64(y)*dx)
-		yo := amplitude * math.Sin(float64(x)*dx)
-		for yn := 0; yn < dotSize; yn++ {
-			r := RandInt(0, dotSize)
-			m.drawCircle(x+int(xo), y+int(yo)+(yn*dotSize), r/2, 1)
-		}
-	}
-}
-
 /**
  * @Description: 画N条随机细线
  * @receiver cd
@@ -0,0 +1,126 @@
+package instance
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/hulutech-web/goravel-captcha/assets"
+)
+
+// SetBackgroundFS is a function
+/**
+ * @Description: 从 fs.FS 设置随机背景图片，支持 embed.FS、os.DirFS 及 NewStorageFS，无需写入磁盘
+ * @receiver cc
+ * @param fsys
+ * @param patterns	glob 匹配规则，例如 "images/*.jpg"，匹配到的文件名作为缓存路径
+ * @return error
+ */
+func (cc *Captcha) SetBackgroundFS(fsys fs.FS, patterns ...string) error {
+	return cc.apply(WithBackgroundFS(fsys, patterns...))
+}
+
+// SetBackgroundDir is a function
+/**
+ * @Description: 从本地目录设置随机背景图片
+ * @receiver cc
+ * @param dir
+ * @param patterns	glob 匹配规则，默认为 "*.jpg"
+ * @return error
+ */
+func (cc *Captcha) SetBackgroundDir(dir string, patterns ...string) error {
+	if has, err := PathExists(dir); !has || err != nil {
+		return fmt.Errorf("CaptchaConfig Error: The [%s] directory does not exist", dir)
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"*.jpg"}
+	}
+
+	return cc.SetBackgroundFS(os.DirFS(dir), patterns...)
+}
+
+// SetFontFS is a function
+/**
+ * @Description: 从 fs.FS 设置随机字体
+ * @receiver cc
+ * @param fsys
+ * @param patterns	glob 匹配规则，例如 "fonts/*.ttf"
+ * @return error
+ */
+func (cc *Captcha) SetFontFS(fsys fs.FS, patterns ...string) error {
+	return cc.applyGlyphs(WithFontFS(fsys, patterns...))
+}
+
+// SetThumbBackgroundFS is a function
+/**
+ * @Description: 从 fs.FS 设置缩略图随机背景图片
+ * @receiver cc
+ * @param fsys
+ * @param patterns	glob 匹配规则
+ * @return error
+ */
+func (cc *Captcha) SetThumbBackgroundFS(fsys fs.FS, patterns ...string) error {
+	return cc.apply(WithThumbBackgroundFS(fsys, patterns...))
+}
+
+/**
+ * @Description: 按 glob 规则读取 fs.FS 中的文件并暂存，配置应用成功后写入资源缓存。
+ * 缓存路径为 "fs/内容摘要/文件名"，不同来源的同名文件互不覆盖，相同内容共用缓存
+ * @receiver c
+ * @param fsys
+ * @param patterns
+ * @return []string	缓存路径
+ * @return error
+ */
+func (c *Config) loadAssetFS(fsys fs.FS, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("CaptchaConfig Error: At least one pattern is required")
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		// 字体集合可以在规则后指定序号，例如 "fonts/*.ttc#1"
+		pattern, index := assets.SplitFontPath(pattern)
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("CaptchaConfig Error: The pattern [%s] is invalid: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("CaptchaConfig Error: The pattern [%s] does not match any file", pattern)
+		}
+
+		for _, path := range matches {
+			bytes, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return nil, fmt.Errorf("CaptchaConfig Error: The [%s] file can not be read: %w", path, err)
+			}
+
+			key := fsAssetKey(path, bytes)
+			name := key
+			if index > 0 {
+				name = fmt.Sprintf("%s#%d", key, index)
+			}
+			if InArrayWithStr(paths, name) {
+				continue
+			}
+			if !c.hasAsset(key) {
+				c.stageAsset(key, bytes)
+			}
+			paths = append(paths, name)
+		}
+	}
+
+	return paths, nil
+}
+
+/**
+ * @Description: fs.FS 中文件的缓存路径，以内容摘要区分来源
+ * @param path
+ * @param content
+ * @return string
+ */
+func fsAssetKey(path string, content []byte) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("fs/%x/%s", sum[:6], path)
+}
@@ -0,0 +1,234 @@
+package instance
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goravel/framework/contracts/filesystem"
+)
+
+// 内存中的 Storage 磁盘，只实现 StorageFS 使用的方法
+type memDriver struct {
+	filesystem.Driver
+	files map[string][]byte
+}
+
+func (d *memDriver) isDir(name string) bool {
+	for file := range d.files {
+		if strings.HasPrefix(file, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *memDriver) Exists(file string) bool {
+	_, ok := d.files[file]
+	return ok || d.isDir(file)
+}
+
+func (d *memDriver) GetBytes(file string) ([]byte, error) {
+	if content, ok := d.files[file]; ok {
+		return bytes.Clone(content), nil
+	}
+	return nil, errors.New("not a file")
+}
+
+func (d *memDriver) Size(file string) (int64, error) {
+	return int64(len(d.files[file])), nil
+}
+
+func (d *memDriver) LastModified(string) (time.Time, error) {
+	return time.Unix(0, 0), nil
+}
+
+// 列出目录下一级的文件或子目录
+func (d *memDriver) children(dir string, dirs bool) []string {
+	prefix := ""
+	if dir != "." {
+		prefix = dir + "/"
+	}
+	seen := map[string]bool{}
+	var list []string
+	for file := range d.files {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(file, prefix)
+		name, _, isDir := strings.Cut(rest, "/")
+		if isDir != dirs || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if isDir {
+			list = append(list, path.Join(dir, name)+"/")
+		} else {
+			list = append(list, path.Join(dir, name))
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (d *memDriver) Files(dir string) ([]string, error) {
+	return d.children(dir, false), nil
+}
+
+func (d *memDriver) Directories(dir string) ([]string, error) {
+	return d.children(dir, true), nil
+}
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStorageFS(t *testing.T) {
+	d := &memDriver{files: map[string][]byte{
+		"images/1.jpg":     testJPEG(t),
+		"images/2.jpg":     testJPEG(t),
+		"images/old/3.jpg": testJPEG(t),
+	}}
+	if err := fstest.TestFS(NewStorageFS(d), "images/1.jpg", "images/2.jpg", "images/old/3.jpg"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAssetFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"images/1.jpg": {Data: []byte("1")},
+		"images/2.jpg": {Data: []byte("2")},
+		"fonts/a.ttf":  {Data: []byte("a")},
+	}
+	var c Config
+	paths, err := c.loadAssetFS(fsys, []string{"images/*.jpg", "images/1.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearAssetCache(paths) })
+	if len(paths) != 2 {
+		t.Fatalf("loadAssetFS() = %v, want each matched file once", paths)
+	}
+	for i, name := range []string{"images/1.jpg", "images/2.jpg"} {
+		want, _ := fs.ReadFile(fsys, name)
+		if paths[i] != fsAssetKey(name, want) {
+			t.Fatalf("loadAssetFS()[%d] = %s, want %s", i, paths[i], fsAssetKey(name, want))
+		}
+		// 配置应用成功前只暂存
+		if hasAssetCache(paths[i]) || !c.hasAsset(paths[i]) {
+			t.Fatalf("[%s] is cached before publishAssets()", paths[i])
+		}
+	}
+	c.publishAssets()
+	for i, name := range []string{"images/1.jpg", "images/2.jpg"} {
+		want, _ := fs.ReadFile(fsys, name)
+		if got, _ := getAssetCache(paths[i]); !bytes.Equal(got, want) {
+			t.Fatalf("cached [%s] = %q, want %q", paths[i], got, want)
+		}
+	}
+
+	for _, patterns := range [][]string{nil, {"images/*.png"}, {"images/["}} {
+		if _, err := c.loadAssetFS(fsys, patterns); err == nil {
+			t.Errorf("loadAssetFS(%q) did not fail", patterns)
+		}
+	}
+}
+
+func TestLoadAssetFSNamespace(t *testing.T) {
+	a := fstest.MapFS{"images/1.jpg": {Data: []byte("a")}}
+	b := fstest.MapFS{"images/1.jpg": {Data: []byte("b")}}
+	var ca, cb Config
+	pa, err := ca.loadAssetFS(a, []string{"images/*.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := cb.loadAssetFS(b, []string{"images/*.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearAssetCache(append(pa, pb...)) })
+	if pa[0] == pb[0] {
+		t.Fatalf("files with different content share the key %s", pa[0])
+	}
+
+	// 不同 fs.FS 中的同名文件互不覆盖
+	ca.publishAssets()
+	cb.publishAssets()
+	if got, _ := getAssetCache(pa[0]); string(got) != "a" {
+		t.Errorf("cached [%s] = %q, want %q", pa[0], got, "a")
+	}
+	if got, _ := getAssetCache(pb[0]); string(got) != "b" {
+		t.Errorf("cached [%s] = %q, want %q", pb[0], got, "b")
+	}
+}
+
+func TestRejectedOptionDoesNotCache(t *testing.T) {
+	content := testJPEG(t)
+	key := fsAssetKey("images/1.jpg", content)
+	d := &memDriver{files: map[string][]byte{"images/1.jpg": content}}
+	t.Cleanup(func() { clearAssetCache([]string{key}) })
+
+	// 被拒绝的选项不写入资源缓存
+	_, err := NewCaptcha(WithBackgroundFS(NewStorageFS(d), "images/*.jpg"), WithImageQuality(200))
+	if err == nil {
+		t.Fatal("NewCaptcha() with an invalid quality did not fail")
+	}
+	if hasAssetCache(key) {
+		t.Fatalf("rejected option cached [%s]", key)
+	}
+}
+
+func TestSetBackgroundFS(t *testing.T) {
+	content := testJPEG(t)
+	key := fsAssetKey("images/1.jpg", content)
+	d := &memDriver{files: map[string][]byte{"images/1.jpg": content}}
+	cc := newTestCaptcha(t)
+	if err := cc.SetBackgroundFS(NewStorageFS(d), "images/*.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearAssetCache([]string{key}) })
+	if cfg := cc.Config(); len(cfg.RangBackground) != 1 || cfg.RangBackground[0] != key {
+		t.Fatalf("RangBackground = %v, want [%s]", cfg.RangBackground, key)
+	}
+	if !hasAssetCache(key) {
+		t.Fatalf("[%s] is not cached after the option applied", key)
+	}
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetBackgroundDir(t *testing.T) {
+	dir := t.TempDir()
+	content := testJPEG(t)
+	if err := os.WriteFile(filepath.Join(dir, "1.jpg"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	key := fsAssetKey("1.jpg", content)
+	cc := newTestCaptcha(t)
+	if err := cc.SetBackgroundDir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("SetBackgroundDir() with a missing directory did not fail")
+	}
+	if err := cc.SetBackgroundDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearAssetCache([]string{key}) })
+	if cfg := cc.Config(); len(cfg.RangBackground) != 1 || cfg.RangBackground[0] != key {
+		t.Fatalf("RangBackground = %v, want [%s]", cfg.RangBackground, key)
+	}
+}
@@ -0,0 +1,262 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+/**
+ * @Description: 图标名称，为文件名去掉扩展名，例如 "icons/bicycle.png" 为 "bicycle"
+ * @param file
+ * @return string
+ */
+func iconName(file string) string {
+	name := path.Base(filepath.ToSlash(file))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+/**
+ * @Description: 按名称查找图标路径
+ * @param cfg
+ * @param name
+ * @return string	没有该图标时为空
+ */
+func iconPath(cfg *Config, name string) string {
+	for _, file := range cfg.Icons {
+		if iconName(file) == name {
+			return file
+		}
+	}
+	return ""
+}
+
+/**
+ * @Description: 所有图标的名称，图标模式下作为随机字符
+ * @param cfg
+ * @return []string
+ */
+func iconNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.Icons))
+	for _, file := range cfg.Icons {
+		names = append(names, iconName(file))
+	}
+	return names
+}
+
+/**
+ * @Description: 检测图标，图标数量不少于 RangTextLen.Max，名称互不相同且不含 ":"
+ * @param cfg
+ * @return error
+ */
+func checkIcons(cfg *Config) error {
+	if cfg.TextMode != TextModeIcon {
+		return nil
+	}
+	if len(cfg.Icons) < cfg.RangTextLen.Max {
+		return fmt.Errorf("CaptchaConfig Error: Only %d icons, RangTextLen.Max is %d", len(cfg.Icons), cfg.RangTextLen.Max)
+	}
+
+	var invalid []string
+	seen := make(map[string]bool, len(cfg.Icons))
+	for _, name := range iconNames(cfg) {
+		if name == "" || strings.Contains(name, ":") || seen[name] {
+			invalid = append(invalid, name)
+		}
+		seen[name] = true
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("CaptchaConfig Error: %d icon names are empty, repeated or contain \":\" [%s]", len(invalid), reportChars(invalid))
+	}
+	return nil
+}
+
+/**
+ * @Description: 检测图标图片，图片可以解码且包含透明像素，不透明的图片只能绘制为色块
+ * @param c		图片从配置暂存的资源或资源缓存读取
+ * @param icons
+ * @return error
+ */
+func checkIconImages(c *Config, icons []string) error {
+	var errs []error
+	for _, file := range icons {
+		img, err := c.assetImage(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CaptchaConfig Error: The [%s] icon can not be decoded: %w", file, err))
+			continue
+		}
+		if isOpaqueImage(img) {
+			errs = append(errs, fmt.Errorf("CaptchaConfig Error: The [%s] icon has no transparent pixel", file))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 图片是否完全不透明
+ * @param img
+ * @return bool
+ */
+func isOpaqueImage(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a < 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/**
+ * @Description: 图标按比例缩放后的尺寸，长边为字号
+ * @param file
+ * @param size
+ * @return int	宽
+ * @return int	高
+ * @return error
+ */
+func iconSize(file string, size int) (int, int, error) {
+	img, err := getAssetImage(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return 0, 0, fmt.Errorf("the [%s] icon is empty", file)
+	}
+	scale := float64(size) / float64(max(b.Dx(), b.Dy()))
+	return max(int(float64(b.Dx())*scale+0.5), 1), max(int(float64(b.Dy())*scale+0.5), 1), nil
+}
+
+/**
+ * @Description: 按图标的透明通道绘制单色图标，与文本一样只使用文本颜色，可以参与旋转、扭曲及对比度调整
+ * @receiver cd
+ * @param dst
+ * @param file	图标路径
+ * @param src	图标颜色
+ * @param r		绘制区域，图标缩放到该区域
+ * @return error
+ */
+func (cd *Draw) drawIcon(dst draw.Image, file string, src image.Image, r image.Rectangle) error {
+	img, err := getAssetImage(file)
+	if err != nil {
+		return err
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.CatmullRom.Scale(mask, mask.Bounds(), img, img.Bounds(), draw.Src, nil)
+	draw.DrawMask(dst, r, src, image.Point{}, mask, image.Point{}, draw.Over)
+	return nil
+}
+
+// WithIcons is a function
+/**
+ * @Description: 设置图标并启用图标模式，图标为透明背景的 PNG，文件名（去掉扩展名）为图标名称
+ * @param icons
+ * @param args	true|false 是否强制刷新缓存
+ * @return Option
+ */
+func WithIcons(icons []string, args ...bool) Option {
+	return func(c *Config) error {
+		if err := c.loadAssetPaths(icons, len(args) > 0 && args[0]); err != nil {
+			return err
+		}
+		if err := checkIconImages(c, icons); err != nil {
+			return err
+		}
+		c.Icons = append([]string(nil), icons...)
+		c.TextMode = TextModeIcon
+		return nil
+	}
+}
+
+// WithIconFS is a function
+/**
+ * @Description: 从 fs.FS 读取图标并启用图标模式
+ * @param fsys
+ * @param patterns	glob 匹配规则，例如 "icons/*.png"
+ * @return Option
+ */
+func WithIconFS(fsys fs.FS, patterns ...string) Option {
+	return func(c *Config) error {
+		icons, err := c.loadAssetFS(fsys, patterns)
+		if err != nil {
+			return err
+		}
+		if err := checkIconImages(c, icons); err != nil {
+			return err
+		}
+		c.Icons = icons
+		c.TextMode = TextModeIcon
+		return nil
+	}
+}
+
+// SetIcons is a function
+/**
+ * @Description: 设置图标并启用图标模式
+ * @receiver cc
+ * @param icons
+ * @param args	true|false 是否强制刷新缓存
+ * @return error
+ */
+func (cc *Captcha) SetIcons(icons []string, args ...bool) error {
+	return cc.applyIcons(WithIcons(icons, args...))
+}
+
+// SetIconFS is a function
+/**
+ * @Description: 从 fs.FS 设置图标并启用图标模式
+ * @receiver cc
+ * @param fsys
+ * @param patterns	glob 匹配规则，例如 "icons/*.png"
+ * @return error
+ */
+func (cc *Captcha) SetIconFS(fsys fs.FS, patterns ...string) error {
+	return cc.applyIcons(WithIconFS(fsys, patterns...))
+}
+
+// SetIconDir is a function
+/**
+ * @Description: 从本地目录设置图标并启用图标模式
+ * @receiver cc
+ * @param dir
+ * @param patterns	glob 匹配规则，默认为 "*.png"
+ * @return error
+ */
+func (cc *Captcha) SetIconDir(dir string, patterns ...string) error {
+	if has, err := PathExists(dir); !has || err != nil {
+		return fmt.Errorf("CaptchaConfig Error: The [%s] directory does not exist", dir)
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"*.png"}
+	}
+	return cc.SetIconFS(os.DirFS(dir), patterns...)
+}
+
+/**
+ * @Description: 应用图标相关的选项，图标检测通过后再替换
+ * @receiver cc
+ * @param opt
+ * @return error
+ */
+func (cc *Captcha) applyIcons(opt Option) error {
+	return cc.apply(func(c *Config) error {
+		if err := opt(c); err != nil {
+			return err
+		}
+		return checkIcons(c)
+	})
+}
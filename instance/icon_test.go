@@ -0,0 +1,92 @@
+package instance
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 写入测试图标，opaque 为 false 时只有中间的方块不透明
+func writeTestIcon(t *testing.T, path string, opaque bool) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if opaque || (x >= 8 && x < 24 && y >= 8 && y < 24) {
+				img.SetNRGBA(x, y, color.NRGBA{R: 0x33, G: 0x66, B: 0x99, A: 0xff})
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearAssetCache([]string{path}) })
+	return path
+}
+
+func testIcons(t *testing.T, dir string, names ...string) []string {
+	t.Helper()
+	icons := make([]string, 0, len(names))
+	for _, name := range names {
+		icons = append(icons, writeTestIcon(t, filepath.Join(dir, name+".png"), false))
+	}
+	return icons
+}
+
+func TestSetIcons(t *testing.T) {
+	dir := t.TempDir()
+	cc := newTestCaptcha(t, WithTextRangLen(RangeVal{4, 5}))
+	icons := testIcons(t, dir, "bicycle", "bus", "car", "plane", "ship")
+	if err := cc.SetIcons(icons); err != nil {
+		t.Fatal(err)
+	}
+
+	dots, _, _, _, err := cc.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := cc.Config()
+	for _, dot := range dots {
+		if iconPath(&cfg, dot.Text) == "" {
+			t.Fatalf("check dot [%s] is not an icon name", dot.Text)
+		}
+	}
+}
+
+func TestSetIconsRejected(t *testing.T) {
+	dir := t.TempDir()
+	cc := newTestCaptcha(t, WithTextRangLen(RangeVal{4, 5}))
+	icons := testIcons(t, dir, "bicycle", "bus", "car", "plane")
+
+	for _, tc := range []struct {
+		name  string
+		icons []string
+		want  string
+	}{
+		{"fewer than RangTextLen.Max", icons, "Only 4 icons"},
+		{"repeated name", append(icons, testIcons(t, filepath.Join(dir, "other"), "bus")...), "repeated"},
+		{"name with a colon", append(icons, testIcons(t, dir, "a:b")...), "contain \":\""},
+		{"opaque image", append(icons, writeTestIcon(t, filepath.Join(dir, "solid.png"), true)), "no transparent pixel"},
+	} {
+		err := cc.SetIcons(tc.icons)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: SetIcons() = %v, want an error containing %q", tc.name, err, tc.want)
+		}
+	}
+	// 被拒绝的图标不会替换配置
+	if cfg := cc.Config(); cfg.TextMode != TextModeRandom || len(cfg.Icons) != 0 {
+		t.Fatalf("rejected SetIcons() replaced the config: mode %d, icons %v", cfg.TextMode, cfg.Icons)
+	}
+}
@@ -0,0 +1,185 @@
+package instance
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// Interference is a type
+/**
+ * @Description: 主图干扰层，绘制在文本之上，颜色取自主图文本颜色，数量为 0 的干扰不绘制
+ */
+type Interference struct {
+	// 穿过文本区域的波浪线数量
+	WaveLines int
+	// 随机弧线数量
+	Arcs int
+	// 噪点数量
+	NoiseDots int
+	// 与文本笔画相似的短笔画数量
+	DecoyStrokes int
+	// 干扰透明度 0-1
+	Alpha float64
+}
+
+/**
+ * @Description: 是否需要绘制干扰
+ * @receiver in
+ * @return bool
+ */
+func (in Interference) empty() bool {
+	return in.WaveLines <= 0 && in.Arcs <= 0 && in.NoiseDots <= 0 && in.DecoyStrokes <= 0
+}
+
+/**
+ * @Description: 在主图上绘制干扰层
+ * @receiver cd
+ * @param m
+ * @param params
+ */
+func (cd *Draw) drawInterference(m *image.RGBA, params DrawCanvas) {
+	in := params.Interference
+	if in.empty() {
+		return
+	}
+
+	b := m.Bounds()
+	// 干扰颜色与文本一致，无法按颜色过滤
+	var colors []color.RGBA
+	// 文本所在的纵向区域及平均字号
+	top, bottom, fontSize := b.Max.Y, b.Min.Y, 0
+	for _, dot := range params.CaptchaDrawDot {
+		co, err := ParseHexColor(dot.Color)
+		if err == nil {
+			colors = append(colors, co)
+		}
+		top = min(top, dot.Dy-dot.Height)
+		bottom = max(bottom, dot.Dy)
+		fontSize += dot.Size
+	}
+	if len(colors) == 0 {
+		colors = append(colors, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	}
+	if len(params.CaptchaDrawDot) > 0 {
+		fontSize /= len(params.CaptchaDrawDot)
+	} else {
+		fontSize = b.Dy() / 3
+	}
+	top = max(top, b.Min.Y)
+	bottom = min(bottom, b.Max.Y-1)
+	if top > bottom {
+		top, bottom = b.Min.Y, b.Max.Y-1
+	}
+
+	mask := image.NewAlpha(b)
+	pick := func() color.RGBA {
+		return colors[RandInt(0, len(colors)-1)]
+	}
+
+	for i := 0; i < in.WaveLines; i++ {
+		y0 := float64(RandInt(top, bottom))
+		amplitude := RandFloat(3, 8)
+		period := RandFloat(60, 160)
+		phase := RandFloat(0, 360) * math.Pi / 180
+		var pts []point2
+		for x := b.Min.X; x <= b.Max.X; x += 2 {
+			pts = append(pts, point2{float64(x), y0 + amplitude*math.Sin(2*math.Pi*float64(x)/period+phase)})
+		}
+		cd.strokePath(m, mask, pts, RandFloat(10, 20)/10, pick(), in.Alpha)
+	}
+
+	for i := 0; i < in.Arcs; i++ {
+		cx := float64(RandInt(b.Min.X, b.Max.X))
+		cy := float64(RandInt(b.Min.Y, b.Max.Y))
+		radius := math.Max(RandFloat(b.Dy()/4, b.Dy()), 4)
+		start := RandFloat(0, 360) * math.Pi / 180
+		span := RandFloat(60, 180) * math.Pi / 180
+		var pts []point2
+		for a := 0.0; a <= span; a += 2 / radius {
+			pts = append(pts, point2{cx + radius*math.Cos(start+a), cy + radius*math.Sin(start+a)})
+		}
+		cd.strokePath(m, mask, pts, RandFloat(8, 16)/10, pick(), in.Alpha)
+	}
+
+	for i := 0; i < in.NoiseDots; i++ {
+		p := point2{float64(RandInt(b.Min.X, b.Max.X-1)), float64(RandInt(b.Min.Y, b.Max.Y-1))}
+		cd.strokePath(m, mask, []point2{p}, RandFloat(8, 16)/10, pick(), in.Alpha)
+	}
+
+	// 二次贝塞尔曲线，长度和粗细接近文本笔画
+	width := math.Max(float64(fontSize)/12, 1.5)
+	for i := 0; i < in.DecoyStrokes; i++ {
+		length := math.Max(RandFloat(fontSize/2, fontSize), 4)
+		angle := RandFloat(0, 360) * math.Pi / 180
+		p0 := point2{float64(RandInt(b.Min.X, b.Max.X-1)), float64(RandInt(top, bottom))}
+		p2 := point2{p0.x + length*math.Cos(angle), p0.y + length*math.Sin(angle)}
+		bend := RandFloat(-fontSize/3, fontSize/3)
+		p1 := point2{(p0.x+p2.x)/2 - bend*math.Sin(angle), (p0.y+p2.y)/2 + bend*math.Cos(angle)}
+		var pts []point2
+		for t := 0.0; t <= 1; t += 1 / length {
+			u := 1 - t
+			pts = append(pts, point2{u*u*p0.x + 2*u*t*p1.x + t*t*p2.x, u*u*p0.y + 2*u*t*p1.y + t*t*p2.y})
+		}
+		cd.strokePath(m, mask, pts, width, pick(), in.Alpha)
+	}
+}
+
+// 浮点坐标
+type point2 struct {
+	x, y float64
+}
+
+/**
+ * @Description: 按折线绘制带抗锯齿的线条，先在遮罩中取覆盖度的最大值，再整体混合，线条重叠处不会加深
+ * @receiver cd
+ * @param m
+ * @param mask	与 m 同尺寸的遮罩，绘制后清空使用过的区域
+ * @param pts
+ * @param width	线宽
+ * @param co
+ * @param alpha
+ */
+func (cd *Draw) strokePath(m *image.RGBA, mask *image.Alpha, pts []point2, width float64, co color.RGBA, alpha float64) {
+	if len(pts) == 0 {
+		return
+	}
+
+	radius := width / 2
+	area := image.Rectangle{}
+	stamp := func(cx, cy float64) {
+		r := image.Rect(int(math.Floor(cx-radius-1)), int(math.Floor(cy-radius-1)), int(math.Ceil(cx+radius+1))+1, int(math.Ceil(cy+radius+1))+1).Intersect(mask.Rect)
+		area = area.Union(r)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+				cov := math.Min(math.Max(radius+0.5-d, 0), 1)
+				v := uint8(cov * alpha * 255)
+				if i := mask.PixOffset(x, y); v > mask.Pix[i] {
+					mask.Pix[i] = v
+				}
+			}
+		}
+	}
+
+	stamp(pts[0].x, pts[0].y)
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		steps := int(math.Ceil(math.Hypot(b.x-a.x, b.y-a.y) * 2))
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			stamp(a.x+(b.x-a.x)*t, a.y+(b.y-a.y)*t)
+		}
+	}
+	if area.Empty() {
+		return
+	}
+
+	draw.DrawMask(m, area, image.NewUniform(co), image.Point{}, mask, area.Min, draw.Over)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		i := mask.PixOffset(area.Min.X, y)
+		clear(mask.Pix[i : i+area.Dx()])
+	}
+}
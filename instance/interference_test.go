@@ -0,0 +1,83 @@
+package instance
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testInterferenceCanvas() *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for i := range m.Pix {
+		m.Pix[i] = 0xff
+	}
+	return m
+}
+
+func TestDrawInterferenceEmpty(t *testing.T) {
+	m := testInterferenceCanvas()
+	before := bytes.Clone(m.Pix)
+	cd := &Draw{}
+	cd.drawInterference(m, DrawCanvas{Interference: Interference{Alpha: 0.8}})
+	if !bytes.Equal(before, m.Pix) {
+		t.Error("drawInterference() changed the image without any layer")
+	}
+}
+
+func TestDrawInterferenceLayers(t *testing.T) {
+	dots := []DrawDot{{Dx: 20, Dy: 60, Size: 30, Width: 30, Height: 30, Color: "#ff0000"}}
+	layers := map[string]Interference{
+		"WaveLines":    {WaveLines: 2, Alpha: 1},
+		"Arcs":         {Arcs: 20, Alpha: 1},
+		"NoiseDots":    {NoiseDots: 20, Alpha: 1},
+		"DecoyStrokes": {DecoyStrokes: 3, Alpha: 1},
+	}
+	for name, in := range layers {
+		m := testInterferenceCanvas()
+		cd := &Draw{}
+		cd.drawInterference(m, DrawCanvas{CaptchaDrawDot: dots, Interference: in})
+
+		// 干扰颜色取自文本颜色
+		changed := 0
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 200; x++ {
+				c := m.RGBAAt(x, y)
+				if c == (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+					continue
+				}
+				changed++
+				if c.R != 0xff || c.G != c.B {
+					t.Fatalf("%s: pixel (%d,%d) = %v, want a tint of the text color", name, x, y, c)
+				}
+			}
+		}
+		if changed == 0 {
+			t.Errorf("%s: drawInterference() changed no pixel", name)
+		}
+	}
+}
+
+func TestInterferenceOptions(t *testing.T) {
+	cc := newTestCaptcha(t)
+	if err := cc.SetImageWaveLineNum(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := cc.SetImageInterferenceAlpha(0.5); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := cc.Config(); cfg.ImageWaveLineNum != 3 || cfg.ImageInterferenceAlpha != 0.5 {
+		t.Errorf("Config() = %d, %v; want 3, 0.5", cfg.ImageWaveLineNum, cfg.ImageInterferenceAlpha)
+	}
+	if err := cc.SetImageArcNum(-1); err == nil {
+		t.Error("SetImageArcNum(-1) returned no error")
+	}
+	for _, alpha := range []float64{0, 1.5} {
+		if err := cc.SetImageInterferenceAlpha(alpha); err == nil {
+			t.Errorf("SetImageInterferenceAlpha(%v) returned no error", alpha)
+		}
+	}
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
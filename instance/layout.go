@@ -0,0 +1,156 @@
+package instance
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// 单个文本的随机位置尝试次数
+const placeAttempts = 100
+
+// 整体重新排布的次数
+const placeRounds = 10
+
+/**
+ * @Description: 计算旋转后文本的占位尺寸，文本四周保留裁剪时的2像素边距，且不超出绘制文本的画布
+ * @param width		文本宽
+ * @param height	文本高
+ * @param angle		旋转角度
+ * @return int
+ * @return int
+ */
+func rotatedBox(width, height, angle int) (int, int) {
+	rad := float64(angle) * math.Pi / 180
+	sin := math.Abs(math.Sin(rad))
+	cos := math.Abs(math.Cos(rad))
+	w := float64(width + 4)
+	h := float64(height + 4)
+
+	rw := int(math.Ceil(w*cos + h*sin))
+	rh := int(math.Ceil(w*sin + h*cos))
+	// 文本在 (width+10)x(height+10) 的画布中旋转，超出画布的部分会被裁掉
+	return min(rw, width+10), min(rh, height+10)
+}
+
+/**
+ * @Description: 按旋转后的占位区域重新排布主图文本，保证任意两个文本的区域不相交且间距不小于 TextMinGap，
+ * 距离图片边缘不小于 TextEdgeMargin。dots 的 Dx/Dy 改为区域左下角，文本尺寸不变，用于绘制
+ * @receiver cc
+ * @param cfg
+ * @param imageSize
+ * @param dots
+ * @param sal				背景干扰度，不为空时每个文本在多个候选位置中选择干扰度最低的位置
+ * @return map[int]CharDot	校验用的点，Width/Height 为占位区域尺寸
+ * @return error			图片尺寸不足以放下所有文本
+ */
+func (cc *Captcha) placeDots(cfg *Config, imageSize Size, dots map[int]CharDot, sal *saliencyMap) (map[int]CharDot, error) {
+	gap := cfg.TextMinGap
+	margin := cfg.TextEdgeMargin
+	areaW := imageSize.Width - margin*2
+	areaH := imageSize.Height - margin*2
+
+	boxes := make(map[int]image.Point, len(dots))
+	order := make([]int, 0, len(dots))
+	need := 0
+	for i, dot := range dots {
+		w, h := rotatedBox(dot.Width, dot.Height, dot.Angle)
+		if w > areaW || h > areaH {
+			return nil, fmt.Errorf("CaptchaConfig Error: ImageSize (%dx%d) is too small for the char [%s] with TextEdgeMargin %d", imageSize.Width, imageSize.Height, dot.Text, margin)
+		}
+		boxes[i] = image.Point{X: w, Y: h}
+		order = append(order, i)
+		need += (w + gap) * (h + gap)
+	}
+	if need > (areaW+gap)*(areaH+gap) {
+		return nil, fmt.Errorf("CaptchaConfig Error: ImageSize (%dx%d) is too small to place %d chars without overlap", imageSize.Width, imageSize.Height, len(dots))
+	}
+
+	// 先放置占位区域大的文本，成功率更高
+	sort.Slice(order, func(a, b int) bool {
+		pa, pb := boxes[order[a]], boxes[order[b]]
+		return pa.X*pa.Y > pb.X*pb.Y
+	})
+
+	for round := 0; round < placeRounds; round++ {
+		placed, ok := cc.tryPlace(order, boxes, imageSize, margin, gap, sal)
+		if !ok {
+			continue
+		}
+
+		answers := make(map[int]CharDot, len(dots))
+		for i, rect := range placed {
+			dot := dots[i]
+			dot.Dx = rect.Min.X
+			dot.Dy = rect.Max.Y
+			dots[i] = dot
+
+			dot.Width = rect.Dx()
+			dot.Height = rect.Dy()
+			answers[i] = dot
+		}
+		return answers, nil
+	}
+
+	return nil, fmt.Errorf("CaptchaConfig Error: Failed to place %d chars without overlap in ImageSize (%dx%d), reduce RangTextLen, RangFontSize or TextMinGap", len(dots), imageSize.Width, imageSize.Height)
+}
+
+/**
+ * @Description: 依次为每个文本随机选取不与已放置区域相交的位置
+ * @receiver cc
+ * @param order
+ * @param boxes
+ * @param imageSize
+ * @param margin
+ * @param gap
+ * @param sal	背景干扰度，为空时使用第一个可用位置
+ * @return map[int]image.Rectangle
+ * @return bool
+ */
+func (cc *Captcha) tryPlace(order []int, boxes map[int]image.Point, imageSize Size, margin, gap int, sal *saliencyMap) (map[int]image.Rectangle, bool) {
+	candidates := 1
+	if sal != nil {
+		candidates = saliencyCandidates
+	}
+
+	placed := make(map[int]image.Rectangle, len(order))
+	for _, i := range order {
+		box := boxes[i]
+		var best image.Rectangle
+		bestCost := math.MaxFloat64
+		found := 0
+		for attempt := 0; attempt < placeAttempts && found < candidates; attempt++ {
+			x := RandInt(margin, imageSize.Width-margin-box.X)
+			y := RandInt(margin, imageSize.Height-margin-box.Y)
+			rect := image.Rect(x, y, x+box.X, y+box.Y)
+			// 扩大间距后检测相交
+			expanded := image.Rect(rect.Min.X-gap, rect.Min.Y-gap, rect.Max.X+gap, rect.Max.Y+gap)
+
+			overlap := false
+			for _, other := range placed {
+				if expanded.Overlaps(other) {
+					overlap = true
+					break
+				}
+			}
+			if overlap {
+				continue
+			}
+
+			found++
+			cost := 0.0
+			if sal != nil {
+				cost = sal.cost(rect)
+			}
+			if cost < bestCost {
+				best, bestCost = rect, cost
+			}
+		}
+		if found == 0 {
+			return nil, false
+		}
+		placed[i] = best
+	}
+	return placed, true
+}
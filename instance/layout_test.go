@@ -0,0 +1,69 @@
+package instance
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func testLayoutDots(n, size int) map[int]CharDot {
+	dots := make(map[int]CharDot, n)
+	for i := 0; i < n; i++ {
+		dots[i] = CharDot{Index: i, Text: "A", Width: size, Height: size, Angle: i * 25}
+	}
+	return dots
+}
+
+func TestPlaceDotsNoOverlap(t *testing.T) {
+	cc := newTestCaptcha(t)
+	cfg := cc.Config()
+	imageSize := Size{Width: 300, Height: 240}
+	bounds := image.Rect(cfg.TextEdgeMargin, cfg.TextEdgeMargin, imageSize.Width-cfg.TextEdgeMargin, imageSize.Height-cfg.TextEdgeMargin)
+
+	for n := 0; n < 100; n++ {
+		answers, err := cc.placeDots(&cfg, imageSize, testLayoutDots(6, 36), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rects := make([]image.Rectangle, 0, len(answers))
+		for _, dot := range answers {
+			rect := image.Rect(dot.Dx, dot.Dy-dot.Height, dot.Dx+dot.Width, dot.Dy)
+			if !rect.In(bounds) {
+				t.Fatalf("box %v is outside %v", rect, bounds)
+			}
+			rects = append(rects, rect)
+		}
+		for i := range rects {
+			for j := i + 1; j < len(rects); j++ {
+				// 间距不小于 TextMinGap
+				if rects[i].Inset(-cfg.TextMinGap).Overlaps(rects[j]) {
+					t.Fatalf("boxes %v and %v are closer than %d", rects[i], rects[j], cfg.TextMinGap)
+				}
+			}
+		}
+	}
+}
+
+func TestPlaceDotsTooSmall(t *testing.T) {
+	cc := newTestCaptcha(t)
+	cfg := cc.Config()
+
+	for _, tc := range []struct {
+		name string
+		size Size
+		dots map[int]CharDot
+	}{
+		{"char larger than the image", Size{Width: 60, Height: 60}, testLayoutDots(1, 60)},
+		{"too many chars", Size{Width: 100, Height: 80}, testLayoutDots(6, 36)},
+	} {
+		_, err := cc.placeDots(&cfg, tc.size, tc.dots, nil)
+		if err == nil || !strings.Contains(err.Error(), "too small") {
+			t.Errorf("%s: placeDots() = %v, want a too small error", tc.name, err)
+		}
+	}
+
+	// 配置检测时提前报错
+	if _, err := NewCaptcha(WithImageSize(Size{Width: 100, Height: 80})); err == nil || !strings.Contains(err.Error(), "too small") {
+		t.Fatalf("NewCaptcha() = %v, want a too small error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package instance
+
+import (
+	"github.com/hulutech-web/goravel-captcha/assets"
+	"golang.org/x/image/font"
+)
+
+/**
+ * @Description: 按字体度量的文本尺寸，单位为像素
+ */
+type textMetrics struct {
+	// 各字符步进宽度之和，包含字距调整
+	Advance int
+	// 基线以上的高度
+	Ascent int
+	// 基线以下的高度
+	Descent int
+}
+
+/**
+ * @Description: 文本占用的高度
+ * @receiver tm
+ * @return int
+ */
+func (tm textMetrics) Height() int {
+	return tm.Ascent + tm.Descent
+}
+
+/**
+ * @Description: 使用字体的步进宽度及上下高度度量文本，字体按配置读取，暂存的字体与字形覆盖的检测一致
+ * @param cfg
+ * @param path	字体路径
+ * @param text
+ * @param size	字号
+ * @param dpi
+ * @return textMetrics
+ * @return error	字体无法读取
+ */
+func measureText(cfg *Config, path, text string, size, dpi int) (textMetrics, error) {
+	f, err := cfg.assetFont(path)
+	if err != nil {
+		return textMetrics{}, err
+	}
+	return measureFont(f, text, size, dpi)
+}
+
+/**
+ * @Description: 使用已读取的字体度量文本，与 DrawStrImg 使用相同的 Hinting
+ * @param f
+ * @param text
+ * @param size	字号
+ * @param dpi
+ * @return textMetrics
+ * @return error
+ */
+func measureFont(f *assets.Font, text string, size, dpi int) (textMetrics, error) {
+	face, err := f.NewFace(float64(size), float64(dpi), font.HintingFull)
+	if err != nil {
+		return textMetrics{}, err
+	}
+	defer face.Close()
+
+	m := face.Metrics()
+	return textMetrics{
+		Advance: font.MeasureString(face, text).Ceil(),
+		Ascent:  m.Ascent.Ceil(),
+		Descent: m.Descent.Ceil(),
+	}, nil
+}
@@ -0,0 +1,89 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeasureText(t *testing.T) {
+	cfg := DefaultConfig()
+	path := cfg.RangFont[0]
+
+	wide, err := measureText(&cfg, path, "WW", 32, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrow, err := measureText(&cfg, path, "ii", 32, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wide.Advance <= narrow.Advance {
+		t.Errorf("Advance(WW) = %d, want greater than Advance(ii) = %d", wide.Advance, narrow.Advance)
+	}
+	// 上下高度只与字体及字号有关
+	if wide.Height() != narrow.Height() || wide.Ascent <= 0 || wide.Descent <= 0 {
+		t.Errorf("metrics = %+v and %+v, want the same positive ascent and descent", wide, narrow)
+	}
+
+	big, err := measureText(&cfg, path, "WW", 64, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if big.Advance <= wide.Advance || big.Height() <= wide.Height() {
+		t.Errorf("metrics at 64 = %+v, want larger than at 32 = %+v", big, wide)
+	}
+
+	if _, err := measureText(&cfg, "missing.ttf", "W", 32, 72); err == nil {
+		t.Error("measureText(missing.ttf) returned no error")
+	}
+}
+
+func TestGenDotsUsesMetrics(t *testing.T) {
+	cc := newTestCaptcha(t)
+	cfg := cc.Config()
+	chars := cfg.RangChars[:4]
+	cov, err := glyphCoverageOf(&cfg, chars, cfg.RangFont)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dots := cc.genDots(&cfg, cov, cfg.ImageSize, cfg.RangFontSize, strings.Join(chars, ":"), 10)
+	for _, dot := range dots {
+		tm, err := measureText(&cfg, dot.Font, dot.Text, dot.Size, cfg.FontDPI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dot.Width != max(tm.Advance, 1) {
+			t.Errorf("[%s] Width = %d, want the measured advance %d", dot.Text, dot.Width, tm.Advance)
+		}
+		if dot.Height < tm.Height() {
+			t.Errorf("[%s] Height = %d, want at least the measured height %d", dot.Text, dot.Height, tm.Height())
+		}
+	}
+}
+
+func TestMeasureTextStagedFont(t *testing.T) {
+	cfg := DefaultConfig()
+	content, err := getAssetCache(cfg.RangFont[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 暂存的字体不在资源缓存中，按配置读取
+	path := "test/staged-metrics.ttf"
+	cfg.stageAsset(path, content)
+	if hasAssetCache(path) {
+		t.Fatalf("[%s] is cached before publishAssets()", path)
+	}
+	staged, err := measureText(&cfg, path, "WW", 32, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := measureText(&cfg, cfg.RangFont[0], "WW", 32, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staged != want {
+		t.Errorf("staged metrics = %+v, want %+v", staged, want)
+	}
+}
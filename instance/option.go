@@ -0,0 +1,688 @@
+package instance
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"golang.org/x/image/font"
+)
+
+// Option is a type
+/**
+ * @Description: 配置选项，修改传入的配置，不合法时返回错误且不应用。
+ * 选项读取的文件只暂存在配置中，应用成功后才写入资源缓存，不影响其他验证码
+ */
+type Option func(c *Config) error
+
+// WithConfig is a function
+/**
+ * @Description: 使用整份配置，通常放在第一个选项，后续选项在其基础上修改
+ * @param cfg
+ * @return Option
+ */
+func WithConfig(cfg Config) Option {
+	return func(c *Config) error {
+		*c = cfg.Clone()
+		return nil
+	}
+}
+
+// WithRangChars is a function
+/**
+ * @Description: 随机字符集合，每个字符的显示宽度不能超出2，即一个中日韩文字或两个拉丁字母
+ * @param chars
+ * @return Option
+ */
+func WithRangChars(chars []string) Option {
+	return func(c *Config) error {
+		if len(chars) == 0 {
+			return fmt.Errorf("Captcha SetRangChars Error: The chars must not be empty")
+		}
+		if err := checkChars(chars); err != nil {
+			return err
+		}
+		c.RangChars = append([]string(nil), chars...)
+		return nil
+	}
+}
+
+// WithTextRangLen is a function
+/**
+ * @Description: 字符随机长度范围
+ * @param val
+ * @return Option
+ */
+func WithTextRangLen(val RangeVal) Option {
+	return func(c *Config) error {
+		if err := checkRangeVal("RangTextLen", val, 1); err != nil {
+			return err
+		}
+		c.RangTextLen = val
+		return nil
+	}
+}
+
+// WithRangCheckTextLen is a function
+/**
+ * @Description: 随机验证文本长度，与随机字符串长度的约束由 Validate 检测
+ * @param val
+ * @return Option
+ */
+func WithRangCheckTextLen(val RangeVal) Option {
+	return func(c *Config) error {
+		if err := checkRangeVal("RangCheckTextLen", val, 1); err != nil {
+			return err
+		}
+		c.RangCheckTextLen = val
+		return nil
+	}
+}
+
+// WithTextRangAnglePos is a function
+/**
+ * @Description: 文本角度位置
+ * @param pos
+ * @return Option
+ */
+func WithTextRangAnglePos(pos []RangeVal) Option {
+	return func(c *Config) error {
+		if err := checkAngles(pos); err != nil {
+			return err
+		}
+		c.RangTexAnglePos = append([]RangeVal(nil), pos...)
+		return nil
+	}
+}
+
+// WithRangFontSize is a function
+/**
+ * @Description: 随机字体大小
+ * @param val
+ * @return Option
+ */
+func WithRangFontSize(val RangeVal) Option {
+	return func(c *Config) error {
+		if err := checkRangeVal("RangFontSize", val, 1); err != nil {
+			return err
+		}
+		c.RangFontSize = val
+		return nil
+	}
+}
+
+// WithRangCheckFontSize is a function
+/**
+ * @Description: 随机验证文本大小
+ * @param val
+ * @return Option
+ */
+func WithRangCheckFontSize(val RangeVal) Option {
+	return func(c *Config) error {
+		if err := checkRangeVal("RangCheckFontSize", val, 1); err != nil {
+			return err
+		}
+		c.RangCheckFontSize = val
+		return nil
+	}
+}
+
+// WithTextRangFontColors is a function
+/**
+ * @Description: 文本随机颜色
+ * @param colors
+ * @return Option
+ */
+func WithTextRangFontColors(colors []string) Option {
+	return func(c *Config) error {
+		if err := checkColors("RangFontColors", colors); err != nil {
+			return err
+		}
+		c.RangFontColors = append([]string(nil), colors...)
+		return nil
+	}
+}
+
+// WithTextShadow is a function
+/**
+ * @Description: 文本阴影显示
+ * @param val
+ * @return Option
+ */
+func WithTextShadow(val bool) Option {
+	return func(c *Config) error {
+		c.ShowTextShadow = val
+		return nil
+	}
+}
+
+// WithTextShadowColor is a function
+/**
+ * @Description: 文本阴影颜色
+ * @param val
+ * @return Option
+ */
+func WithTextShadowColor(val string) Option {
+	return func(c *Config) error {
+		if err := checkColor("TextShadowColor", val); err != nil {
+			return err
+		}
+		c.TextShadowColor = val
+		return nil
+	}
+}
+
+// WithTextShadowPoint is a function
+/**
+ * @Description: 文本阴影位置
+ * @param val
+ * @return Option
+ */
+func WithTextShadowPoint(val Point) Option {
+	return func(c *Config) error {
+		c.TextShadowPoint = val
+		return nil
+	}
+}
+
+// WithThumbTextRangFontColors is a function
+/**
+ * @Description: 缩略图文本随机颜色
+ * @param colors
+ * @return Option
+ */
+func WithThumbTextRangFontColors(colors []string) Option {
+	return func(c *Config) error {
+		if err := checkColors("RangThumbFontColors", colors); err != nil {
+			return err
+		}
+		c.RangThumbFontColors = append([]string(nil), colors...)
+		return nil
+	}
+}
+
+// WithFont is a function
+/**
+ * @Description: 随机字体，读取文件，应用成功后写入资源缓存
+ * @param fonts
+ * @param args	true|false 是否强制刷新缓存
+ * @return Option
+ */
+func WithFont(fonts []string, args ...bool) Option {
+	return func(c *Config) error {
+		if err := c.loadAssetPaths(fonts, len(args) > 0 && args[0]); err != nil {
+			return err
+		}
+		c.RangFont = append([]string(nil), fonts...)
+		return nil
+	}
+}
+
+// WithFontFS is a function
+/**
+ * @Description: 从 fs.FS 读取随机字体
+ * @param fsys
+ * @param patterns	glob 匹配规则，例如 "fonts/*.ttf"
+ * @return Option
+ */
+func WithFontFS(fsys fs.FS, patterns ...string) Option {
+	return func(c *Config) error {
+		fonts, err := c.loadAssetFS(fsys, patterns)
+		if err != nil {
+			return err
+		}
+		c.RangFont = fonts
+		return nil
+	}
+}
+
+// WithFontDPI is a function
+/**
+ * @Description: 分辨率，72为标准
+ * @param val
+ * @return Option
+ */
+func WithFontDPI(val int) Option {
+	return func(c *Config) error {
+		if val <= 0 {
+			return fmt.Errorf("CaptchaConfig Error: FontDPI must be greater than 0")
+		}
+		c.FontDPI = val
+		return nil
+	}
+}
+
+// WithBackground is a function
+/**
+ * @Description: 随机背景图片，读取文件，应用成功后写入资源缓存，可以混入 Background... 程序生成的背景
+ * @param images
+ * @param args	true|false 是否强制刷新缓存
+ * @return Option
+ */
+func WithBackground(images []string, args ...bool) Option {
+	return func(c *Config) error {
+		// 程序生成的背景不需要读取文件
+		var files []string
+		for _, image := range images {
+			if isProceduralBackground(image) {
+				if !slices.Contains(ProceduralBackgrounds(), image) {
+					return fmt.Errorf("CaptchaConfig Error: The [%s] procedural background does not exist", image)
+				}
+				continue
+			}
+			files = append(files, image)
+		}
+		if len(files) > 0 || len(images) == 0 {
+			if err := c.loadAssetPaths(files, len(args) > 0 && args[0]); err != nil {
+				return err
+			}
+		}
+		c.RangBackground = append([]string(nil), images...)
+		return nil
+	}
+}
+
+// WithBackgroundFS is a function
+/**
+ * @Description: 从 fs.FS 读取随机背景图片
+ * @param fsys
+ * @param patterns	glob 匹配规则，例如 "images/*.jpg"
+ * @return Option
+ */
+func WithBackgroundFS(fsys fs.FS, patterns ...string) Option {
+	return func(c *Config) error {
+		images, err := c.loadAssetFS(fsys, patterns)
+		if err != nil {
+			return err
+		}
+		c.RangBackground = images
+		return nil
+	}
+}
+
+// WithImageSize is a function
+/**
+ * @Description: 验证码尺寸
+ * @param size
+ * @return Option
+ */
+func WithImageSize(size Size) Option {
+	return func(c *Config) error {
+		if err := checkSize("ImageSize", size); err != nil {
+			return err
+		}
+		c.ImageSize = size
+		return nil
+	}
+}
+
+// WithImageQuality is a function
+/**
+ * @Description: 验证码清晰度
+ * @param val
+ * @return Option
+ */
+func WithImageQuality(val int) Option {
+	return func(c *Config) error {
+		if err := checkQuality(val); err != nil {
+			return err
+		}
+		c.ImageQuality = val
+		return nil
+	}
+}
+
+// WithImageFontDistort is a function
+/**
+ * @Description: 文本扭曲程度
+ * @param val
+ * @return Option
+ */
+func WithImageFontDistort(val int) Option {
+	return func(c *Config) error {
+		if err := checkDistort("ImageFontDistort", val); err != nil {
+			return err
+		}
+		c.ImageFontDistort = val
+		return nil
+	}
+}
+
+// WithImageFontAlpha is a function
+/**
+ * @Description: 文本透明度
+ * @param val
+ * @return Option
+ */
+func WithImageFontAlpha(val float64) Option {
+	return func(c *Config) error {
+		if err := checkAlpha(val); err != nil {
+			return err
+		}
+		c.ImageFontAlpha = val
+		return nil
+	}
+}
+
+// WithThumbSize is a function
+/**
+ * @Description: 缩略图尺寸
+ * @param size
+ * @return Option
+ */
+func WithThumbSize(size Size) Option {
+	return func(c *Config) error {
+		if err := checkSize("ThumbnailSize", size); err != nil {
+			return err
+		}
+		c.ThumbnailSize = size
+		return nil
+	}
+}
+
+// WithFontHinting is a function
+/**
+ * @Description: 字体Hinting
+ * @param val
+ * @return Option
+ */
+func WithFontHinting(val font.Hinting) Option {
+	return func(c *Config) error {
+		c.FontHinting = val
+		return nil
+	}
+}
+
+// WithThumbBackground is a function
+/**
+ * @Description: 缩略图随机背景图片，读取文件，应用成功后写入资源缓存
+ * @param images
+ * @param args	true|false 是否强制刷新缓存
+ * @return Option
+ */
+func WithThumbBackground(images []string, args ...bool) Option {
+	return func(c *Config) error {
+		if err := c.loadAssetPaths(images, len(args) > 0 && args[0]); err != nil {
+			return err
+		}
+		c.RangThumbBackground = append([]string(nil), images...)
+		return nil
+	}
+}
+
+// WithThumbBackgroundFS is a function
+/**
+ * @Description: 从 fs.FS 读取缩略图随机背景图片
+ * @param fsys
+ * @param patterns	glob 匹配规则
+ * @return Option
+ */
+func WithThumbBackgroundFS(fsys fs.FS, patterns ...string) Option {
+	return func(c *Config) error {
+		images, err := c.loadAssetFS(fsys, patterns)
+		if err != nil {
+			return err
+		}
+		c.RangThumbBackground = images
+		return nil
+	}
+}
+
+// WithThumbBgColors is a function
+/**
+ * @Description: 缩略图随机背景颜色
+ * @param colors
+ * @return Option
+ */
+func WithThumbBgColors(colors []string) Option {
+	return func(c *Config) error {
+		if err := checkColors("RangThumbBgColors", colors); err != nil {
+			return err
+		}
+		c.RangThumbBgColors = append([]string(nil), colors...)
+		return nil
+	}
+}
+
+// WithThumbBgDistort is a function
+/**
+ * @Description: 缩略图扭曲程度
+ * @param val
+ * @return Option
+ */
+func WithThumbBgDistort(val int) Option {
+	return func(c *Config) error {
+		if err := checkDistort("ThumbBgDistort", val); err != nil {
+			return err
+		}
+		c.ThumbBgDistort = val
+		return nil
+	}
+}
+
+// WithThumbFontDistort is a function
+/**
+ * @Description: 缩略图字体扭曲程度
+ * @param val
+ * @return Option
+ */
+func WithThumbFontDistort(val int) Option {
+	return func(c *Config) error {
+		if err := checkDistort("ThumbFontDistort", val); err != nil {
+			return err
+		}
+		c.ThumbFontDistort = val
+		return nil
+	}
+}
+
+// WithThumbBgCirclesNum is a function
+/**
+ * @Description: 缩略图小圆点数量
+ * @param val
+ * @return Option
+ */
+func WithThumbBgCirclesNum(val int) Option {
+	return func(c *Config) error {
+		if val < 0 {
+			return fmt.Errorf("CaptchaConfig Error: ThumbBgCirclesNum must be greater than or equal to 0")
+		}
+		c.ThumbBgCirclesNum = val
+		return nil
+	}
+}
+
+// WithThumbBgSlimLineNum is a function
+/**
+ * @Description: 缩略图线条数量
+ * @param val
+ * @return Option
+ */
+func WithThumbBgSlimLineNum(val int) Option {
+	return func(c *Config) error {
+		if val < 0 {
+			return fmt.Errorf("CaptchaConfig Error: ThumbBgSlimLineNum must be greater than or equal to 0")
+		}
+		c.ThumbBgSlimLineNum = val
+		return nil
+	}
+}
+
+// WithTextMinGap is a function
+/**
+ * @Description: 主图文本占位区域之间的最小间距
+ * @param val
+ * @return Option
+ */
+func WithTextMinGap(val int) Option {
+	return func(c *Config) error {
+		if val < 0 {
+			return fmt.Errorf("CaptchaConfig Error: TextMinGap must be greater than or equal to 0")
+		}
+		c.TextMinGap = val
+		return nil
+	}
+}
+
+// WithTextEdgeMargin is a function
+/**
+ * @Description: 主图文本距离图片边缘的最小距离
+ * @param val
+ * @return Option
+ */
+func WithTextEdgeMargin(val int) Option {
+	return func(c *Config) error {
+		if val < 0 {
+			return fmt.Errorf("CaptchaConfig Error: TextEdgeMargin must be greater than or equal to 0")
+		}
+		c.TextEdgeMargin = val
+		return nil
+	}
+}
+
+// WithTextPlacement is a function
+/**
+ * @Description: 主图文本排布方式，PlacementSaliency 会避开背景中细节多或与文本颜色相近的区域
+ * @param val
+ * @return Option
+ */
+func WithTextPlacement(val int) Option {
+	return func(c *Config) error {
+		if err := checkPlacement(val); err != nil {
+			return err
+		}
+		c.TextPlacement = val
+		return nil
+	}
+}
+
+// WithTextContrast is a function
+/**
+ * @Description: 主图文本与背景的最低对比度 1-21，按背景为每个文本选择对比度最高的颜色，
+ * 颜色都不满足时调整亮度，0 为不调整
+ * @param val
+ * @return Option
+ */
+func WithTextContrast(val float64) Option {
+	return func(c *Config) error {
+		if err := checkContrast(val); err != nil {
+			return err
+		}
+		c.TextContrast = val
+		return nil
+	}
+}
+
+// WithThumbBgFillColor is a function
+/**
+ * @Description: 缩略图底色，为空时透明，设置缩略图背景图时不生效
+ * @param val	格式："#541245"
+ * @return Option
+ */
+func WithThumbBgFillColor(val string) Option {
+	return func(c *Config) error {
+		if val != "" {
+			if err := checkColor("ThumbBgFillColor", val); err != nil {
+				return err
+			}
+		}
+		c.ThumbBgFillColor = val
+		return nil
+	}
+}
+
+// WithImageWaveLineNum is a function
+/**
+ * @Description: 主图穿过文本区域的波浪线数量，0 为不绘制
+ * @param val
+ * @return Option
+ */
+func WithImageWaveLineNum(val int) Option {
+	return func(c *Config) error {
+		if err := checkInterferenceNum("ImageWaveLineNum", val); err != nil {
+			return err
+		}
+		c.ImageWaveLineNum = val
+		return nil
+	}
+}
+
+// WithImageArcNum is a function
+/**
+ * @Description: 主图随机弧线数量，0 为不绘制
+ * @param val
+ * @return Option
+ */
+func WithImageArcNum(val int) Option {
+	return func(c *Config) error {
+		if err := checkInterferenceNum("ImageArcNum", val); err != nil {
+			return err
+		}
+		c.ImageArcNum = val
+		return nil
+	}
+}
+
+// WithImageNoiseDotNum is a function
+/**
+ * @Description: 主图噪点数量，0 为不绘制
+ * @param val
+ * @return Option
+ */
+func WithImageNoiseDotNum(val int) Option {
+	return func(c *Config) error {
+		if err := checkInterferenceNum("ImageNoiseDotNum", val); err != nil {
+			return err
+		}
+		c.ImageNoiseDotNum = val
+		return nil
+	}
+}
+
+// WithImageDecoyStrokeNum is a function
+/**
+ * @Description: 主图与文本笔画相似的干扰笔画数量，0 为不绘制
+ * @param val
+ * @return Option
+ */
+func WithImageDecoyStrokeNum(val int) Option {
+	return func(c *Config) error {
+		if err := checkInterferenceNum("ImageDecoyStrokeNum", val); err != nil {
+			return err
+		}
+		c.ImageDecoyStrokeNum = val
+		return nil
+	}
+}
+
+// WithImageInterferenceAlpha is a function
+/**
+ * @Description: 主图干扰透明度 0-1
+ * @param val
+ * @return Option
+ */
+func WithImageInterferenceAlpha(val float64) Option {
+	return func(c *Config) error {
+		if err := checkInterferenceAlpha(val); err != nil {
+			return err
+		}
+		c.ImageInterferenceAlpha = val
+		return nil
+	}
+}
+
+// WithBackgroundAugment is a function
+/**
+ * @Description: 背景增强强度 0-1，同一张背景图每次生成的像素都不同，0 为不处理
+ * @param val
+ * @return Option
+ */
+func WithBackgroundAugment(val float64) Option {
+	return func(c *Config) error {
+		if err := checkAugment(val); err != nil {
+			return err
+		}
+		c.BackgroundAugment = val
+		return nil
+	}
+}
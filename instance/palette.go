@@ -4,6 +4,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"sync"
 )
 
 // Point is a type
@@ -22,6 +23,14 @@ type Palette struct {
 	*image.Paletted
 }
 
+// 旋转、扭曲时复用的像素缓冲区
+var pixBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]uint8, 0)
+		return &buf
+	},
+}
+
 // NewPalette is a function
 /**
  * @Description: 创建调色板
@@ -35,30 +44,124 @@ func NewPalette(r image.Rectangle, p color.Palette) *Palette {
 	}
 }
 
+// 旋转时每个不透明颜色追加的透明度级数
+const rotateAlphaLevels = 16
+
 // Rotate is a function
 /**
- * @Description: 旋转任意角度
+ * @Description: 旋转任意角度，双线性采样四个相邻像素。透明度按四个像素的权重混合，
+ * 调色板中为每个不透明颜色追加 rotateAlphaLevels 级透明度，边缘输出半透明的索引，合成时与背景混合实现抗锯齿。
+ * 调色板无法表示任意混合色，颜色取权重最大的颜色；调色板已满时只能按覆盖率过半取舍，没有抗锯齿
  * @receiver p
  * @param angle
  */
 func (p *Palette) Rotate(angle int) {
-	tarImg := p
-	width := tarImg.Bounds().Max.X
-	height := tarImg.Bounds().Max.Y
-	r := width / 2
-	retImg := image.NewPaletted(image.Rect(0, 0, width, height), tarImg.Palette)
-	for x := 0; x <= retImg.Bounds().Max.X; x++ {
-		for y := 0; y <= retImg.Bounds().Max.Y; y++ {
-			tx, ty := p.angleSwapPoint(float64(x), float64(y), float64(r), float64(angle))
-			retImg.SetColorIndex(x, y, tarImg.ColorIndexAt(int(tx), int(ty)))
+	width := p.Rect.Dx()
+	height := p.Rect.Dy()
+	if width == 0 || height == 0 {
+		return
+	}
+
+	src := getPixBuffer(len(p.Pix))
+	defer putPixBuffer(src)
+	copy(src, p.Pix)
+
+	// 每个索引的透明度，以及追加的透明度级别的起始索引
+	var alpha [256]float64
+	var ramp [256]int
+	var opaque []int
+	for i, c := range p.Palette {
+		if i >= len(alpha) {
+			break
+		}
+		if _, _, _, a := c.RGBA(); a > 0 {
+			alpha[i] = float64(a) / 0xffff
+			opaque = append(opaque, i)
+		}
+	}
+	levels := 1
+	if len(opaque) > 0 {
+		levels = min(rotateAlphaLevels, (256-len(p.Palette))/len(opaque)+1)
+	}
+	if levels > 1 {
+		for _, i := range opaque {
+			ramp[i] = len(p.Palette)
+			nc := color.NRGBAModel.Convert(p.Palette[i]).(color.NRGBA)
+			for l := 1; l < levels; l++ {
+				c := nc
+				c.A = uint8(float64(nc.A) * float64(l) / float64(levels))
+				p.Palette = append(p.Palette, c)
+			}
 		}
 	}
 
-	nW := retImg.Bounds().Max.X
-	nH := retImg.Bounds().Max.Y
-	for x := 0; x < nW; x++ {
-		for y := 0; y < nH; y++ {
-			p.SetColorIndex(x, y, retImg.ColorIndexAt(x, y))
+	stride := p.Stride
+	r := float64(width / 2)
+	sinVal := math.Sin(float64(angle) * (math.Pi / 180))
+	cosVal := math.Cos(float64(angle) * (math.Pi / 180))
+
+	// 读取源像素索引，越界视为透明
+	at := func(x, y int) uint8 {
+		if x < 0 || y < 0 || x >= width || y >= height {
+			return 0
+		}
+		return src[y*stride+x]
+	}
+
+	var idx [4]uint8
+	var weight [4]float64
+	for y := 0; y < height; y++ {
+		row := p.Pix[y*stride : y*stride+width]
+		for x := 0; x < width; x++ {
+			// 与 angleSwapPoint 相同的坐标变换
+			ox := float64(x) - r
+			oy := r - float64(y)
+			tx := ox*cosVal + oy*sinVal + r
+			ty := r - (-ox*sinVal + oy*cosVal)
+
+			x0 := int(math.Floor(tx))
+			y0 := int(math.Floor(ty))
+			fx := tx - float64(x0)
+			fy := ty - float64(y0)
+
+			idx[0], weight[0] = at(x0, y0), (1-fx)*(1-fy)
+			idx[1], weight[1] = at(x0+1, y0), fx*(1-fy)
+			idx[2], weight[2] = at(x0, y0+1), (1-fx)*fy
+			idx[3], weight[3] = at(x0+1, y0+1), fx*fy
+
+			// 透明度按权重混合，颜色取透明度权重最大的索引
+			a := 0.0
+			best := uint8(0)
+			bestWeight := 0.0
+			for i := 0; i < 4; i++ {
+				if alpha[idx[i]] == 0 {
+					continue
+				}
+				w := weight[i] * alpha[idx[i]]
+				a += w
+				// 同一索引的权重合并
+				for j := 0; j < i; j++ {
+					if idx[j] == idx[i] {
+						w += weight[j] * alpha[idx[j]]
+					}
+				}
+				if w > bestWeight {
+					best, bestWeight = idx[i], w
+				}
+			}
+
+			level := 0
+			if bestWeight > 0 {
+				level = min(int(math.Round(a/alpha[best]*float64(levels))), levels)
+			}
+			switch level {
+			case 0:
+				row[x] = 0
+			case levels:
+				row[x] = best
+			default:
+				row[x] = uint8(ramp[best] + level - 1)
+			}
 		}
 	}
 }
@@ -119,27 +222,58 @@ func (p *Palette) drawHorizLine(fromX, toX, y int, colorIdx uint8) {
  * @param period
  */
 func (p *Palette) distort(amplude float64, period float64) {
-	w := p.Bounds().Max.X
-	h := p.Bounds().Max.Y
-	newP := NewPalette(image.Rect(0, 0, w, h), p.Palette)
+	w := p.Rect.Dx()
+	h := p.Rect.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+
+	src := getPixBuffer(len(p.Pix))
+	defer putPixBuffer(src)
+	copy(src, p.Pix)
+
+	// x 偏移只与行相关，y 偏移只与列相关，预先计算
 	dx := 2.0 * math.Pi / period
+	xOffsets := make([]int, h)
+	for y := 0; y < h; y++ {
+		xOffsets[y] = int(amplude * math.Sin(float64(y)*dx))
+	}
+	yOffsets := make([]int, w)
 	for x := 0; x < w; x++ {
-		for y := 0; y < h; y++ {
-			xo := amplude * math.Sin(float64(y)*dx)
-			yo := amplude * math.Cos(float64(x)*dx)
-			newP.SetColorIndex(x, y, p.ColorIndexAt(x+int(xo), y+int(yo)))
-		}
+		yOffsets[x] = int(amplude * math.Cos(float64(x)*dx))
 	}
 
-	nW := newP.Bounds().Max.X
-	nH := newP.Bounds().Max.Y
-	for x := 0; x < nW; x++ {
-		for y := 0; y < nH; y++ {
-			p.SetColorIndex(x, y, newP.ColorIndexAt(x, y))
+	stride := p.Stride
+	for y := 0; y < h; y++ {
+		row := p.Pix[y*stride : y*stride+w]
+		xo := xOffsets[y]
+		for x := 0; x < w; x++ {
+			sx := x + xo
+			sy := y + yOffsets[x]
+			if sx < 0 || sy < 0 || sx >= w || sy >= h {
+				row[x] = 0
+				continue
+			}
+			row[x] = src[sy*stride+sx]
 		}
 	}
+}
 
-	newP.Palette = nil
+/**
+ * @Description: 调色板中每个索引是否不透明
+ * @receiver p
+ * @return [256]bool
+ */
+func (p *Palette) opaqueTable() (table [256]bool) {
+	for i, c := range p.Palette {
+		if i >= len(table) {
+			break
+		}
+		if _, _, _, a := c.RGBA(); a > 0 {
+			table[i] = true
+		}
+	}
+	return
 }
 
 /**
@@ -202,3 +336,24 @@ func (p *Palette) angleSwapPoint(x, y, r, angle float64) (tarX, tarY float64) {
 	tarY = r - tarY
 	return
 }
+
+/**
+ * @Description: 从缓冲池获取长度为 n 的像素缓冲区
+ * @param n
+ * @return []uint8
+ */
+func getPixBuffer(n int) []uint8 {
+	buf := *pixBufferPool.Get().(*[]uint8)
+	if cap(buf) < n {
+		buf = make([]uint8, n)
+	}
+	return buf[:n]
+}
+
+/**
+ * @Description: 归还像素缓冲区
+ * @param buf
+ */
+func putPixBuffer(buf []uint8) {
+	pixBufferPool.Put(&buf)
+}
@@ -0,0 +1,77 @@
+package instance
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// 中间为实心方块的调色板画布
+func testSquarePalette(p color.Palette) *Palette {
+	pa := NewPalette(image.Rect(0, 0, 60, 60), p)
+	for y := 20; y < 40; y++ {
+		for x := 20; x < 40; x++ {
+			pa.SetColorIndex(x, y, 1)
+		}
+	}
+	return pa
+}
+
+func TestPaletteRotateAntialias(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	pa := testSquarePalette(color.Palette{color.RGBA{}, red})
+	pa.Rotate(30)
+
+	partial, solid := 0, 0
+	for _, idx := range pa.Pix {
+		_, _, _, a := pa.Palette[idx].RGBA()
+		switch {
+		case a == 0xffff:
+			solid++
+		case a > 0:
+			partial++
+			// 边缘只改变透明度，颜色不变
+			if c := color.NRGBAModel.Convert(pa.Palette[idx]).(color.NRGBA); c.R != 0xff || c.G != 0 || c.B != 0 {
+				t.Fatalf("edge color = %v, want a translucent red", c)
+			}
+		}
+	}
+	if solid == 0 || partial == 0 {
+		t.Fatalf("Rotate(30) = %d solid and %d partial pixels, want both", solid, partial)
+	}
+	// 覆盖面积与方块面积相近
+	if area := solid + partial/2; area < 360 || area > 440 {
+		t.Errorf("Rotate(30) covers about %d pixels, want about 400", area)
+	}
+}
+
+func TestPaletteRotateZero(t *testing.T) {
+	pa := testSquarePalette(color.Palette{color.RGBA{}, color.RGBA{B: 0xff, A: 0xff}})
+	want := append([]uint8(nil), pa.Pix...)
+	pa.Rotate(0)
+	for i := range want {
+		if pa.Pix[i] != want[i] {
+			t.Fatalf("Rotate(0) changed pixel %d from %d to %d", i, want[i], pa.Pix[i])
+		}
+	}
+}
+
+func TestPaletteRotateFullPalette(t *testing.T) {
+	p := make(color.Palette, 256)
+	p[0] = color.RGBA{}
+	for i := 1; i < len(p); i++ {
+		p[i] = color.RGBA{R: uint8(i), A: 0xff}
+	}
+	pa := testSquarePalette(p)
+	pa.Rotate(30)
+
+	// 调色板已满时不追加透明度级别，按覆盖率过半取舍
+	if len(pa.Palette) != 256 {
+		t.Fatalf("Rotate() grew a full palette to %d colors", len(pa.Palette))
+	}
+	for _, idx := range pa.Pix {
+		if idx != 0 && idx != 1 {
+			t.Fatalf("Rotate() wrote index %d, want 0 or 1", idx)
+		}
+	}
+}
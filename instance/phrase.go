@@ -0,0 +1,326 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/**
+ * @Description: 验证文本的生成方式
+ */
+const (
+	// 从主图文本中随机选取验证文本
+	TextModeRandom = iota
+	// 验证文本为词库中的词语或成语，字符散布在主图的干扰字符之间，按阅读顺序点选
+	TextModePhrase
+	// 验证文本为图标，从图标集中随机选取，按缩略图顺序点选
+	TextModeIcon
+)
+
+// 内置词库，常用四字成语，每个成语中的字符互不相同
+var defaultPhrases = strings.Fields(`
+画蛇添足 守株待兔 亡羊补牢 对牛弹琴 掩耳盗铃 井底之蛙 狐假虎威 刻舟求剑 杯弓蛇影 叶公好龙 望梅止渴 卧薪尝胆
+破釜沉舟 指鹿为马 纸上谈兵 胸有成竹 入木三分 画龙点睛 一鸣惊人 四面楚歌 完璧归赵 负荆请罪 闻鸡起舞 鹏程万里
+滥竽充数 自相矛盾 拔苗助长 坐井观天 惊弓之鸟 愚公移山 精卫填海 夸父逐日 三顾茅庐 草船借箭 卧虎藏龙 龙飞凤舞
+春暖花开 鸟语花香 风和日丽 万紫千红 山清水秀 五湖四海 四海为家 与时俱进 持之以恒 循序渐进 学以致用 温故知新
+举一反三 废寝忘食 专心致志 全神贯注 一丝不苟 脚踏实地 实事求是 自强不息 厚德载物 海纳百川 同舟共济 齐心协力
+众志成城 不言而喻 兴高采烈 欢天喜地 心旷神怡 一帆风顺 马到成功 水滴石穿 积少成多 聚沙成塔 安居乐业 国泰民安
+风调雨顺 丰衣足食 喜出望外 名列前茅 雪中送炭 锦上添花 如鱼得水 轻而易举 迫不及待 光明磊落 各抒己见 集思广益
+`)
+
+// DefaultPhrases is a function
+/**
+ * @Description: 内置词库
+ * @return []string
+ */
+func DefaultPhrases() []string {
+	return append([]string(nil), defaultPhrases...)
+}
+
+/**
+ * @Description: 词语模式使用的词库，未设置时使用内置词库
+ * @receiver c
+ * @return []string
+ */
+func (c Config) phraseList() []string {
+	if len(c.Phrases) > 0 {
+		return c.Phrases
+	}
+	return defaultPhrases
+}
+
+/**
+ * @Description: 需要检测字形的字符，词语模式下包含词库中的字符
+ * @param cfg
+ * @return []string
+ */
+func glyphChars(cfg *Config) []string {
+	if cfg.TextMode != TextModePhrase {
+		return cfg.RangChars
+	}
+
+	seen := make(map[string]bool, len(cfg.RangChars))
+	chars := append([]string(nil), cfg.RangChars...)
+	for _, char := range chars {
+		seen[char] = true
+	}
+	for _, phrase := range cfg.phraseList() {
+		for _, char := range splitChars(phrase) {
+			if !seen[char] {
+				seen[char] = true
+				chars = append(chars, char)
+			}
+		}
+	}
+	return chars
+}
+
+/**
+ * @Description: 验证文本的生成方式
+ * @param val
+ * @return error
+ */
+func checkTextMode(val int) error {
+	if val == TextModeRandom || val == TextModePhrase || val == TextModeIcon {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: TextMode must be TextModeRandom, TextModePhrase or TextModeIcon")
+}
+
+/**
+ * @Description: 检测词库，每个词语 2 至 RangTextLen.Min 个字符，字符合法且互不相同，缩略图能放下最长的词语
+ * @param cfg
+ * @return error
+ */
+func checkPhrases(cfg *Config) error {
+	if cfg.TextMode != TextModePhrase {
+		return nil
+	}
+
+	var errs []error
+	var invalid []string
+	longest := 0
+	for _, phrase := range cfg.phraseList() {
+		chars := splitChars(phrase)
+		longest = max(longest, len(chars))
+		if len(chars) < 2 || len(chars) > cfg.RangTextLen.Min || checkChars(chars) != nil || hasRepeatChar(chars) {
+			invalid = append(invalid, phrase)
+		}
+	}
+	if len(invalid) > 0 {
+		errs = append(errs, fmt.Errorf("CaptchaConfig Error: %d phrases must have 2 to RangTextLen.Min (%d) distinct chars [%s]", len(invalid), cfg.RangTextLen.Min, reportChars(invalid)))
+	}
+	if need := longest * cfg.RangCheckFontSize.Min; need > cfg.ThumbnailSize.Width {
+		errs = append(errs, fmt.Errorf("CaptchaConfig Error: ThumbnailSize.Width (%d) is too small for phrases of %d chars of size %d", cfg.ThumbnailSize.Width, longest, cfg.RangCheckFontSize.Min))
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 是否有重复的字符，重复的字符无法区分点选顺序
+ * @param chars
+ * @return bool
+ */
+func hasRepeatChar(chars []string) bool {
+	seen := make(map[string]bool, len(chars))
+	for _, char := range chars {
+		if seen[char] {
+			return true
+		}
+		seen[char] = true
+	}
+	return false
+}
+
+/**
+ * @Description: 读取词库文件，每行一个词语，忽略空行及 # 开头的注释
+ * @param path
+ * @return []string
+ * @return error
+ */
+func readPhraseFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("CaptchaConfig Error: The [%s] phrase file can not be read: %w", path, err)
+	}
+
+	var phrases []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrases = append(phrases, line)
+	}
+	if len(phrases) == 0 {
+		return nil, fmt.Errorf("CaptchaConfig Error: The [%s] phrase file has no phrase", path)
+	}
+	return phrases, nil
+}
+
+/**
+ * @Description: 随机选取字形都可用的词语
+ * @param cfg
+ * @param cov
+ * @return []string	词语的字符，没有可用的词语时为空
+ */
+func randPhrase(cfg *Config, cov *glyphCoverage) []string {
+	phrases := cfg.phraseList()
+	for _, i := range RandPerm(len(phrases)) {
+		chars := splitChars(phrases[i])
+		if len(cov.usable(chars)) == len(chars) {
+			return chars
+		}
+	}
+	return nil
+}
+
+/**
+ * @Description: 生成词语及干扰字符，词语在前，干扰字符不与词语中的字符相同或形近
+ * @receiver cc
+ * @param chars		可用的随机字符
+ * @param phrase	词语的字符
+ * @param length	主图文本总数
+ * @return string
+ */
+func (cc *Captcha) genPhraseChars(chars []string, phrase []string, length int) string {
+	decoys := make([]string, 0, len(chars))
+	for _, char := range chars {
+		if !InArrayWithStr(phrase, char) && !hasSimilarChar(phrase, char) {
+			decoys = append(decoys, char)
+		}
+	}
+
+	str := strings.Join(phrase, ":")
+	if rest := cc.genRandChar(decoys, length-len(phrase)); rest != "" {
+		str += ":" + rest
+	}
+	return str
+}
+
+/**
+ * @Description: 词语的字符按阅读顺序作为验证点
+ * @receiver cc
+ * @param dots		主图文本，前 n 个为词语的字符
+ * @param n			词语的字符数
+ * @return map[int]CharDot
+ * @return string
+ */
+func (cc *Captcha) phraseCheckDots(dots map[int]CharDot, n int) (map[int]CharDot, string) {
+	chkDots := make(map[int]CharDot, n)
+	var chars []string
+	for i := 0; i < n; i++ {
+		chars = cc.appendCheckDot(chkDots, chars, dots[i])
+	}
+	return chkDots, strings.Join(chars, ":")
+}
+
+// WithTextMode is a function
+/**
+ * @Description: 验证文本的生成方式，TextModePhrase 时使用词库中的词语，未设置词库时使用内置成语，TextModeIcon 时使用图标
+ * @param val	TextModeRandom|TextModePhrase|TextModeIcon
+ * @return Option
+ */
+func WithTextMode(val int) Option {
+	return func(c *Config) error {
+		if err := checkTextMode(val); err != nil {
+			return err
+		}
+		c.TextMode = val
+		return nil
+	}
+}
+
+// WithPhrases is a function
+/**
+ * @Description: 设置词库并启用词语模式
+ * @param phrases
+ * @return Option
+ */
+func WithPhrases(phrases []string) Option {
+	return func(c *Config) error {
+		var list []string
+		for _, phrase := range phrases {
+			if phrase = strings.TrimSpace(phrase); phrase != "" {
+				list = append(list, phrase)
+			}
+		}
+		if len(list) == 0 {
+			return fmt.Errorf("CaptchaConfig Error: The phrases must not be empty")
+		}
+		c.Phrases = list
+		c.TextMode = TextModePhrase
+		return nil
+	}
+}
+
+// WithPhraseFile is a function
+/**
+ * @Description: 从词库文件读取词语并启用词语模式，文件每行一个词语
+ * @param path
+ * @return Option
+ */
+func WithPhraseFile(path string) Option {
+	return func(c *Config) error {
+		phrases, err := readPhraseFile(path)
+		if err != nil {
+			return err
+		}
+		return WithPhrases(phrases)(c)
+	}
+}
+
+// SetTextMode is a function
+/**
+ * @Description: 设置验证文本的生成方式
+ * @receiver cc
+ * @param val
+ * @return error
+ */
+func (cc *Captcha) SetTextMode(val int) error {
+	return cc.applyPhrases(func(c *Config) error {
+		if err := WithTextMode(val)(c); err != nil {
+			return err
+		}
+		return checkIcons(c)
+	})
+}
+
+// SetPhrases is a function
+/**
+ * @Description: 设置词库并启用词语模式
+ * @receiver cc
+ * @param phrases
+ * @return error
+ */
+func (cc *Captcha) SetPhrases(phrases []string) error {
+	return cc.applyPhrases(WithPhrases(phrases))
+}
+
+// SetPhraseFile is a function
+/**
+ * @Description: 从词库文件设置词语并启用词语模式
+ * @receiver cc
+ * @param path
+ * @return error
+ */
+func (cc *Captcha) SetPhraseFile(path string) error {
+	return cc.applyPhrases(WithPhraseFile(path))
+}
+
+/**
+ * @Description: 应用词库相关的选项，词库及字形覆盖检测通过后再替换
+ * @receiver cc
+ * @param opt
+ * @return error
+ */
+func (cc *Captcha) applyPhrases(opt Option) error {
+	return cc.applyGlyphs(func(c *Config) error {
+		if err := opt(c); err != nil {
+			return err
+		}
+		return checkPhrases(c)
+	})
+}
@@ -0,0 +1,84 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+)
+
+// 从默认字符中取互不形近的字符组成词语
+func testPhrases(n, size int) []string {
+	var picked []string
+	for _, char := range DefaultConfig().RangChars {
+		if len(picked) == n*size {
+			break
+		}
+		if !hasSimilarChar(picked, char) && !InArrayWithStr(picked, char) {
+			picked = append(picked, char)
+		}
+	}
+	phrases := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		phrases = append(phrases, strings.Join(picked[i*size:(i+1)*size], ""))
+	}
+	return phrases
+}
+
+func TestPhraseCheckDotsOrder(t *testing.T) {
+	cc := newTestCaptcha(t)
+	dots := make(map[int]CharDot)
+	for i, char := range []string{"a", "b", "c", "x", "y"} {
+		dots[i] = CharDot{Index: i, Text: char, Dx: i * 40}
+	}
+
+	chkDots, str := cc.phraseCheckDots(dots, 3)
+	if str != "a:b:c" || len(chkDots) != 3 {
+		t.Fatalf("phraseCheckDots() = %v, %q", chkDots, str)
+	}
+	for i := 0; i < 3; i++ {
+		if chkDots[i].Index != i || chkDots[i].Text != dots[i].Text || chkDots[i].Dx != dots[i].Dx {
+			t.Fatalf("check dot %d = %+v, want %+v", i, chkDots[i], dots[i])
+		}
+	}
+}
+
+func TestGeneratePhraseOrder(t *testing.T) {
+	phrases := testPhrases(3, 3)
+	cc := newTestCaptcha(t, WithTextRangLen(RangeVal{4, 5}), WithPhrases(phrases))
+
+	for n := 0; n < 10; n++ {
+		dots, _, _, _, err := cc.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		chars := make([]string, 0, len(dots))
+		for i := 0; i < len(dots); i++ {
+			chars = append(chars, dots[i].Text)
+		}
+		// 验证点按词语的阅读顺序排列
+		if got := strings.Join(chars, ""); !InArrayWithStr(phrases, got) {
+			t.Fatalf("check chars %q are not one of the phrases %v in order", got, phrases)
+		}
+	}
+}
+
+func TestCheckPhrasesLength(t *testing.T) {
+	valid := testPhrases(2, 4)
+	chars := splitChars(valid[0])
+	for _, tc := range []struct {
+		name   string
+		phrase string
+	}{
+		{"single char", chars[0]},
+		{"longer than RangTextLen.Min", valid[0] + valid[1]},
+		{"repeated char", chars[0] + chars[1] + chars[0]},
+	} {
+		_, err := NewCaptcha(WithTextRangLen(RangeVal{4, 5}), WithPhrases([]string{valid[1], tc.phrase}))
+		if err == nil || !strings.Contains(err.Error(), "RangTextLen.Min") {
+			t.Errorf("%s: NewCaptcha() = %v, want a phrase length error", tc.name, err)
+		}
+	}
+
+	if _, err := NewCaptcha(WithTextRangLen(RangeVal{4, 5}), WithPhrases(valid)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,219 @@
+package instance
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PoolConfig is a type
+/**
+ * @Description: 预渲染池配置
+ */
+type PoolConfig struct {
+	// 池中保留的验证码数量
+	Size int
+	// 后台渲染协程数量
+	Workers int
+	// 验证码最大存活时间，超时后丢弃重新渲染，0 为不过期
+	MaxAge time.Duration
+}
+
+// Challenge is a type
+/**
+ * @Description: 预渲染好的验证码
+ */
+type Challenge struct {
+	// 校验点
+	Dots map[int]CharDot
+	// 主图Base64
+	Image string
+	// 缩略图Base64
+	ThumbImage string
+	// 验证码KEY
+	Key string
+	// 渲染时间
+	CreatedAt time.Time
+}
+
+// Pool is a type
+/**
+ * @Description: 验证码预渲染池，后台协程保持池中有 N 个可用的验证码
+ */
+type Pool struct {
+	captcha *Captcha
+	config  PoolConfig
+	queue   chan *Challenge
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewPool is a function
+/**
+ * @Description: 创建预渲染池，需调用 Start 启动
+ * @param cc
+ * @param config
+ * @return *Pool
+ */
+func NewPool(cc *Captcha, config PoolConfig) *Pool {
+	if config.Size <= 0 {
+		config.Size = 1
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+
+	return &Pool{
+		captcha: cc,
+		config:  config,
+		queue:   make(chan *Challenge, config.Size),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start is a function
+/**
+ * @Description: 启动后台渲染协程
+ * @receiver p
+ */
+func (p *Pool) Start() {
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	if p.config.MaxAge > 0 {
+		p.wg.Add(1)
+		go p.evict()
+	}
+}
+
+// Get is a function
+/**
+ * @Description: 从池中取出一个未过期的验证码，池为空时返回 false
+ * @receiver p
+ * @return *Challenge
+ * @return bool
+ */
+func (p *Pool) Get() (*Challenge, bool) {
+	for {
+		select {
+		case ch := <-p.queue:
+			if p.expired(ch) {
+				continue
+			}
+			return ch, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// Len is a function
+/**
+ * @Description: 池中当前的验证码数量
+ * @receiver p
+ * @return int
+ */
+func (p *Pool) Len() int {
+	return len(p.queue)
+}
+
+// Shutdown is a function
+/**
+ * @Description: 停止后台渲染协程，等待正在渲染的任务结束或 ctx 超时
+ * @receiver p
+ * @param ctx
+ * @return error
+ */
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/**
+ * @Description: 渲染协程，池满时阻塞等待取出后再补充
+ * @receiver p
+ */
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+
+		ch, err := p.captcha.renderChallenge()
+		if err != nil {
+			// 渲染失败时稍后重试，避免空转
+			select {
+			case <-p.done:
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		select {
+		case p.queue <- ch:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+/**
+ * @Description: 定期丢弃池中过期的验证码，由渲染协程补充
+ * @receiver p
+ */
+func (p *Pool) evict() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.MaxAge / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			for n := len(p.queue); n > 0; n-- {
+				select {
+				case ch := <-p.queue:
+					if p.expired(ch) {
+						continue
+					}
+					select {
+					case p.queue <- ch:
+					default:
+					}
+				default:
+				}
+			}
+		}
+	}
+}
+
+/**
+ * @Description: 验证码是否已过期
+ * @receiver p
+ * @param ch
+ * @return bool
+ */
+func (p *Pool) expired(ch *Challenge) bool {
+	return p.config.MaxAge > 0 && time.Since(ch.CreatedAt) > p.config.MaxAge
+}
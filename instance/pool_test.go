@@ -0,0 +1,163 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestCaptcha(t *testing.T, opts ...Option) *Captcha {
+	t.Helper()
+	cc, err := NewCaptcha(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cc
+}
+
+func TestPoolRefill(t *testing.T) {
+	p := NewPool(newTestCaptcha(t), PoolConfig{Size: 2, Workers: 2})
+	p.Start()
+	defer p.Shutdown(context.Background())
+
+	waitFor(t, 10*time.Second, func() bool { return p.Len() == 2 })
+	ch, ok := p.Get()
+	if !ok || ch.Key == "" || ch.Image == "" || ch.ThumbImage == "" || len(ch.Dots) == 0 {
+		t.Fatalf("Get() = %+v, %v", ch, ok)
+	}
+	// 取出后由渲染协程补充
+	waitFor(t, 10*time.Second, func() bool { return p.Len() == 2 })
+}
+
+func TestPoolLifecycle(t *testing.T) {
+	p := NewPool(newTestCaptcha(t), PoolConfig{Size: 2, Workers: 1, MaxAge: time.Second})
+	p.Start()
+
+	waitFor(t, 10*time.Second, func() bool { return p.Len() == 2 })
+	first, ok := p.Get()
+	if !ok {
+		t.Fatal("Get() on a full pool returned nothing")
+	}
+	waitFor(t, 10*time.Second, func() bool { return p.Len() == 2 })
+
+	// 超过 MaxAge 的验证码被丢弃，取出的验证码都未过期
+	time.Sleep(1500 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		waitFor(t, 10*time.Second, func() bool { return p.Len() > 0 })
+		ch, ok := p.Get()
+		if !ok || ch.Key == first.Key || time.Since(ch.CreatedAt) > time.Second {
+			t.Fatalf("Get() = %+v, %v, want a challenge younger than MaxAge", ch, ok)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Shutdown 返回时渲染协程已退出，不再补充
+	n := p.Len()
+	time.Sleep(200 * time.Millisecond)
+	if p.Len() != n {
+		t.Fatalf("pool length changed from %d to %d after Shutdown()", n, p.Len())
+	}
+}
+
+func TestPoolGetSkipsExpired(t *testing.T) {
+	p := NewPool(newTestCaptcha(t), PoolConfig{Size: 2, MaxAge: time.Minute})
+	p.queue <- &Challenge{Key: "stale", CreatedAt: time.Now().Add(-2 * time.Minute)}
+	p.queue <- &Challenge{Key: "fresh", CreatedAt: time.Now()}
+
+	ch, ok := p.Get()
+	if !ok || ch.Key != "fresh" {
+		t.Fatalf("Get() = %+v, %v, want the fresh challenge", ch, ok)
+	}
+	if _, ok := p.Get(); ok {
+		t.Fatal("Get() on an empty pool returned a challenge")
+	}
+}
+
+func TestPoolEvictExpired(t *testing.T) {
+	p := NewPool(newTestCaptcha(t), PoolConfig{Size: 2, MaxAge: 20 * time.Millisecond})
+	p.queue <- &Challenge{Key: "a", CreatedAt: time.Now()}
+	p.queue <- &Challenge{Key: "b", CreatedAt: time.Now()}
+
+	// 只启动清理协程，不补充新的验证码
+	p.wg.Add(1)
+	go p.evict()
+	defer p.Shutdown(context.Background())
+
+	waitFor(t, 5*time.Second, func() bool { return p.Len() == 0 })
+}
+
+func TestGenerateWithEmptyPool(t *testing.T) {
+	cc := newTestCaptcha(t)
+	// 池未启动，始终为空
+	p := NewPool(cc, PoolConfig{Size: 1})
+	cc.mu.Lock()
+	cc.pool = p
+	cc.mu.Unlock()
+
+	dots, b64, tb64, key, err := cc.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dots) == 0 || b64 == "" || tb64 == "" || key == "" {
+		t.Fatal("Generate() with an empty pool did not render synchronously")
+	}
+	if p.Len() != 0 {
+		t.Fatalf("pool length = %d, want 0", p.Len())
+	}
+}
+
+func TestPoolShutdownDeadline(t *testing.T) {
+	p := NewPool(newTestCaptcha(t), PoolConfig{Size: 1})
+	// 模拟一个没有结束的渲染任务
+	p.wg.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Shutdown() returned after %v, want it to honour the deadline", d)
+	}
+
+	p.wg.Done()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown() = %v", err)
+	}
+}
+
+func TestPoolShutdownStopsWorkers(t *testing.T) {
+	cc := newTestCaptcha(t)
+	p := cc.StartPool(PoolConfig{Size: 1, Workers: 2, MaxAge: time.Minute})
+	waitFor(t, 10*time.Second, func() bool { return p.Len() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := cc.ClosePool(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 渲染协程已退出，取出后不再补充
+	p.Get()
+	time.Sleep(100 * time.Millisecond)
+	if p.Len() != 0 {
+		t.Fatalf("pool length = %d after shutdown, want 0", p.Len())
+	}
+}
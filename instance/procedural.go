@@ -0,0 +1,332 @@
+package instance
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	mRand "math/rand"
+	"slices"
+	"strings"
+)
+
+// 程序生成背景的名称前缀
+const proceduralPrefix = "procedural:"
+
+/**
+ * @Description: 程序生成的背景，可以与背景图片一起放入 RangBackground
+ */
+const (
+	// 多色线性渐变
+	BackgroundGradient = proceduralPrefix + "gradient"
+	// Perlin 噪声纹理
+	BackgroundNoise = proceduralPrefix + "noise"
+	// 渐变上叠加半透明随机多边形
+	BackgroundPolygons = proceduralPrefix + "polygons"
+	// 随机角度的条纹
+	BackgroundStripes = proceduralPrefix + "stripes"
+)
+
+// ProceduralBackgrounds is a function
+/**
+ * @Description: 所有程序生成的背景
+ * @return []string
+ */
+func ProceduralBackgrounds() []string {
+	return []string{BackgroundGradient, BackgroundNoise, BackgroundPolygons, BackgroundStripes}
+}
+
+/**
+ * @Description: 是否为程序生成的背景
+ * @param name
+ * @return bool
+ */
+func isProceduralBackground(name string) bool {
+	return strings.HasPrefix(name, proceduralPrefix)
+}
+
+// WithProceduralBackground is a function
+/**
+ * @Description: 只使用程序生成的背景，不依赖背景图片，为空时使用全部程序背景。需要与图片混合时，将名称与图片路径一起传入 WithBackground
+ * @param kinds	Background...
+ * @return Option
+ */
+func WithProceduralBackground(kinds ...string) Option {
+	return func(c *Config) error {
+		if len(kinds) == 0 {
+			kinds = ProceduralBackgrounds()
+		}
+		for _, kind := range kinds {
+			if !slices.Contains(ProceduralBackgrounds(), kind) {
+				return fmt.Errorf("CaptchaConfig Error: The [%s] procedural background does not exist", kind)
+			}
+		}
+		c.RangBackground = append([]string(nil), kinds...)
+		return nil
+	}
+}
+
+// SetProceduralBackground is a function
+/**
+ * @Description: 设置只使用程序生成的背景
+ * @receiver cc
+ * @param kinds
+ * @return error
+ */
+func (cc *Captcha) SetProceduralBackground(kinds ...string) error {
+	return cc.apply(WithProceduralBackground(kinds...))
+}
+
+/**
+ * @Description: 读取背景，名称为空时随机使用程序背景，图片读取失败时使用程序背景代替
+ * @receiver cc
+ * @param name
+ * @param size
+ * @return image.Image
+ */
+func (cc *Captcha) loadBackground(name string, size Size) image.Image {
+	if name != "" && !isProceduralBackground(name) {
+		if img, err := getAssetImage(name); err == nil {
+			return img
+		}
+		name = ""
+	}
+	if name == "" {
+		kinds := ProceduralBackgrounds()
+		name = kinds[RandInt(0, len(kinds)-1)]
+	}
+	// 每个验证码使用独立的种子
+	return genProceduralBackground(name, size, int64(RandInt(0, math.MaxInt32)))
+}
+
+/**
+ * @Description: 生成背景图，相同的名称、尺寸和种子生成相同的图片
+ * @param name
+ * @param size
+ * @param seed
+ * @return *image.RGBA
+ */
+func genProceduralBackground(name string, size Size, seed int64) *image.RGBA {
+	r := mRand.New(mRand.NewSource(seed))
+	img := image.NewRGBA(image.Rect(0, 0, size.Width, size.Height))
+
+	// 基础色相随机，配色在色相环上相邻，饱和度及亮度适中，文本颜色在其上都能辨认
+	hue := r.Float64()
+	palette := make([]color.RGBA, 3)
+	for i := range palette {
+		h := math.Mod(hue+float64(i)*(0.08+r.Float64()*0.12), 1)
+		palette[i] = hslToRgb(h, 0.35+r.Float64()*0.35, 0.3+r.Float64()*0.4)
+	}
+
+	switch name {
+	case BackgroundNoise:
+		fillNoise(img, r, palette)
+	case BackgroundPolygons:
+		fillGradient(img, r, palette)
+		fillPolygons(img, r, palette, 8+r.Intn(10))
+	case BackgroundStripes:
+		fillStripes(img, r, palette)
+	default:
+		fillGradient(img, r, palette)
+	}
+	return img
+}
+
+/**
+ * @Description: 随机角度的多色线性渐变
+ * @param img
+ * @param r
+ * @param palette
+ */
+func fillGradient(img *image.RGBA, r *mRand.Rand, palette []color.RGBA) {
+	b := img.Bounds()
+	angle := r.Float64() * 2 * math.Pi
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	// 投影到渐变方向后归一化到 0-1
+	lo, hi := math.MaxFloat64, -math.MaxFloat64
+	for _, p := range []image.Point{b.Min, {X: b.Max.X, Y: b.Min.Y}, {X: b.Min.X, Y: b.Max.Y}, b.Max} {
+		v := float64(p.X)*dx + float64(p.Y)*dy
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			t := (float64(x)*dx + float64(y)*dy - lo) / (hi - lo)
+			img.SetRGBA(x, y, gradientAt(palette, t))
+		}
+	}
+}
+
+/**
+ * @Description: 多层 Perlin 噪声，按噪声值在配色之间插值
+ * @param img
+ * @param r
+ * @param palette
+ */
+func fillNoise(img *image.RGBA, r *mRand.Rand, palette []color.RGBA) {
+	perm := r.Perm(256)
+	perm = append(perm, perm...)
+	b := img.Bounds()
+	scale := 1 / (20 + r.Float64()*40)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v, amp, freq, total := 0.0, 1.0, scale, 0.0
+			for octave := 0; octave < 4; octave++ {
+				v += perlin(perm, float64(x)*freq, float64(y)*freq) * amp
+				total += amp
+				amp /= 2
+				freq *= 2
+			}
+			// perlin 的范围约为 [-0.7, 0.7]
+			t := math.Min(math.Max(v/total/1.4+0.5, 0), 1)
+			img.SetRGBA(x, y, gradientAt(palette, t))
+		}
+	}
+}
+
+/**
+ * @Description: 叠加半透明的随机多边形
+ * @param img
+ * @param r
+ * @param palette
+ * @param num
+ */
+func fillPolygons(img *image.RGBA, r *mRand.Rand, palette []color.RGBA, num int) {
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	for i := 0; i < num; i++ {
+		cx, cy := r.Float64()*w, r.Float64()*h
+		radius := (0.1 + r.Float64()*0.3) * math.Max(w, h)
+		sides := 3 + r.Intn(4)
+		start := r.Float64() * 2 * math.Pi
+		pts := make([]point2, sides)
+		for s := range pts {
+			a := start + float64(s)*2*math.Pi/float64(sides) + (r.Float64()-0.5)*0.6
+			d := radius * (0.6 + r.Float64()*0.4)
+			pts[s] = point2{cx + d*math.Cos(a), cy + d*math.Sin(a)}
+		}
+
+		co := palette[r.Intn(len(palette))]
+		// 略微调亮或调暗，多边形之间可以区分
+		hue, sat, l := rgbToHsl(co)
+		co = hslToRgb(hue, sat, math.Min(math.Max(l+(r.Float64()-0.5)*0.3, 0.1), 0.9))
+		fillPolygon(img, pts, co, 0.25+r.Float64()*0.35)
+	}
+}
+
+/**
+ * @Description: 随机角度、宽度的条纹
+ * @param img
+ * @param r
+ * @param palette
+ */
+func fillStripes(img *image.RGBA, r *mRand.Rand, palette []color.RGBA) {
+	b := img.Bounds()
+	angle := r.Float64() * math.Pi
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	width := 6 + r.Float64()*18
+	// 每条条纹的颜色预先确定，并在相邻条纹之间做 1 像素的过渡
+	n := int(math.Hypot(float64(b.Dx()), float64(b.Dy()))/width) + 2
+	colors := make([]color.RGBA, n)
+	for i := range colors {
+		co := palette[i%len(palette)]
+		h, s, l := rgbToHsl(co)
+		colors[i] = hslToRgb(h, s, math.Min(math.Max(l+(r.Float64()-0.5)*0.15, 0.1), 0.9))
+	}
+
+	lo := math.Min(0, math.Min(float64(b.Max.X)*dx, float64(b.Max.Y)*dy))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := (float64(x)*dx + float64(y)*dy - lo) / width
+			i := min(int(v), n-1)
+			co := colors[i]
+			if f := v - float64(i); f > 1-1/width && i+1 < n {
+				co = blendColor(colors[i+1], co, (f-(1-1/width))*width)
+			}
+			img.SetRGBA(x, y, co)
+		}
+	}
+}
+
+/**
+ * @Description: 在配色之间线性插值
+ * @param palette
+ * @param t	0-1
+ * @return color.RGBA
+ */
+func gradientAt(palette []color.RGBA, t float64) color.RGBA {
+	pos := t * float64(len(palette)-1)
+	i := min(int(pos), len(palette)-2)
+	return blendColor(palette[i+1], palette[i], pos-float64(i))
+}
+
+/**
+ * @Description: 按扫描线填充多边形，与底图按透明度混合
+ * @param img
+ * @param pts
+ * @param co
+ * @param alpha
+ */
+func fillPolygon(img *image.RGBA, pts []point2, co color.RGBA, alpha float64) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		fy := float64(y) + 0.5
+		var xs []float64
+		for i := range pts {
+			p, q := pts[i], pts[(i+1)%len(pts)]
+			if (p.y <= fy) == (q.y <= fy) {
+				continue
+			}
+			xs = append(xs, p.x+(fy-p.y)*(q.x-p.x)/(q.y-p.y))
+		}
+		slices.Sort(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			from := max(int(math.Ceil(xs[i]-0.5)), b.Min.X)
+			to := min(int(math.Floor(xs[i+1]-0.5)), b.Max.X-1)
+			for x := from; x <= to; x++ {
+				img.SetRGBA(x, y, blendColor(co, img.RGBAAt(x, y), alpha))
+			}
+		}
+	}
+}
+
+/**
+ * @Description: 二维 Perlin 噪声
+ * @param perm	512 长度的置换表
+ * @param x
+ * @param y
+ * @return float64
+ */
+func perlin(perm []int, x, y float64) float64 {
+	xi, yi := int(math.Floor(x))&255, int(math.Floor(y))&255
+	xf, yf := x-math.Floor(x), y-math.Floor(y)
+	fade := func(t float64) float64 {
+		return t * t * t * (t*(t*6-15) + 10)
+	}
+	grad := func(hash int, x, y float64) float64 {
+		switch hash & 3 {
+		case 0:
+			return x + y
+		case 1:
+			return -x + y
+		case 2:
+			return x - y
+		}
+		return -x - y
+	}
+	lerp := func(a, b, t float64) float64 {
+		return a + t*(b-a)
+	}
+
+	u, v := fade(xf), fade(yf)
+	aa := perm[perm[xi]+yi]
+	ab := perm[perm[xi]+yi+1]
+	ba := perm[perm[xi+1]+yi]
+	bb := perm[perm[xi+1]+yi+1]
+	return lerp(
+		lerp(grad(aa, xf, yf), grad(ba, xf-1, yf), u),
+		lerp(grad(ab, xf, yf-1), grad(bb, xf-1, yf-1), u),
+		v,
+	) / 2
+}
@@ -0,0 +1,46 @@
+package instance
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenProceduralBackgroundSeed(t *testing.T) {
+	size := Size{Width: 120, Height: 80}
+	for _, name := range ProceduralBackgrounds() {
+		a := genProceduralBackground(name, size, 42)
+		b := genProceduralBackground(name, size, 42)
+		if a.Bounds().Dx() != size.Width || a.Bounds().Dy() != size.Height {
+			t.Errorf("%s: bounds = %v, want %v", name, a.Bounds(), size)
+		}
+		if !bytes.Equal(a.Pix, b.Pix) {
+			t.Errorf("%s: the same seed produced different images", name)
+		}
+		c := genProceduralBackground(name, size, 43)
+		if bytes.Equal(a.Pix, c.Pix) {
+			t.Errorf("%s: different seeds produced the same image", name)
+		}
+	}
+}
+
+func TestProceduralBackgroundOption(t *testing.T) {
+	cc := newTestCaptcha(t)
+	if err := cc.SetProceduralBackground("procedural:missing"); err == nil {
+		t.Error("SetProceduralBackground(missing) returned no error")
+	}
+	if err := cc.SetProceduralBackground(BackgroundNoise, BackgroundStripes); err != nil {
+		t.Fatal(err)
+	}
+	if got := cc.Config().RangBackground; len(got) != 2 || got[0] != BackgroundNoise || got[1] != BackgroundStripes {
+		t.Errorf("RangBackground = %v", got)
+	}
+	if err := cc.SetProceduralBackground(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cc.Config().RangBackground; len(got) != len(ProceduralBackgrounds()) {
+		t.Errorf("RangBackground = %v, want all procedural backgrounds", got)
+	}
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,191 @@
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultProfile 默认配置名称，未指定配置时使用
+const DefaultProfile = "default"
+
+// DefaultTTL 验证码默认有效期，与缓存清理周期一致
+const DefaultTTL = time.Minute * 5
+
+// Profile is a type
+/**
+ * @Description: 命名验证码配置，例如 login、register、comment，每个配置使用独立的验证码实例
+ */
+type Profile struct {
+	// 配置名称
+	Name string
+	// 验证码实例
+	Captcha *Captcha
+	// 验证码有效期
+	TTL time.Duration
+}
+
+var (
+	_profiles   = make(map[string]*Profile)
+	_profilesMu sync.RWMutex
+)
+
+// RegisterProfile is a function
+/**
+ * @Description: 注册命名配置，同名配置会被替换
+ * @param name
+ * @param cc
+ * @param ttl	有效期，小于等于0时使用 DefaultTTL
+ * @return error
+ */
+func RegisterProfile(name string, cc *Captcha, ttl time.Duration) error {
+	if name == "" {
+		return fmt.Errorf("CaptchaProfile Error: The name must not be empty")
+	}
+	if cc == nil {
+		return fmt.Errorf("CaptchaProfile Error: The [%s] profile has no captcha", name)
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	_profilesMu.Lock()
+	defer _profilesMu.Unlock()
+	_profiles[name] = &Profile{Name: name, Captcha: cc, TTL: ttl}
+	return nil
+}
+
+// GetProfile is a function
+/**
+ * @Description: 获取命名配置，名称为空时使用默认配置，默认配置未注册时使用 InitCaptcha
+ * @param name
+ * @return *Profile
+ * @return bool
+ */
+func GetProfile(name string) (*Profile, bool) {
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	_profilesMu.RLock()
+	p, ok := _profiles[name]
+	_profilesMu.RUnlock()
+	if ok {
+		return p, true
+	}
+
+	if name == DefaultProfile {
+		return &Profile{Name: DefaultProfile, Captcha: InitCaptcha(), TTL: DefaultTTL}, true
+	}
+	return nil, false
+}
+
+// Profiles is a function
+/**
+ * @Description: 获取所有已注册的配置，按名称排序
+ * @return []*Profile
+ */
+func Profiles() []*Profile {
+	_profilesMu.RLock()
+	defer _profilesMu.RUnlock()
+
+	list := make([]*Profile, 0, len(_profiles))
+	for _, p := range _profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+/**
+ * @Description: 所有配置中最长的有效期，缓存清理不能早于该时间
+ * @return time.Duration
+ */
+func maxProfileTTL() time.Duration {
+	_profilesMu.RLock()
+	defer _profilesMu.RUnlock()
+
+	ttl := DefaultTTL
+	for _, p := range _profiles {
+		if p.TTL > ttl {
+			ttl = p.TTL
+		}
+	}
+	return ttl
+}
+
+/**
+ * @Description: 缓存中的验证码记录，记录签发的配置和过期时间
+ */
+type cacheRecord struct {
+	Profile   string          `json:"profile"`
+	ExpiresAt int64           `json:"expires_at"`
+	Padding   int             `json:"padding"`
+	Dots      map[int]CharDot `json:"dots"`
+}
+
+// MakeCaptchaWithProfile is a function
+/**
+ * @Description: 使用命名配置生成验证码并写入缓存
+ * @param name
+ * @return interface{}	位置信息
+ * @return string		主图Base64
+ * @return string		缩略图Base64
+ * @return string		验证码KEY
+ * @return error
+ */
+func MakeCaptchaWithProfile(name string) (interface{}, string, string, string, error) {
+	p, ok := GetProfile(name)
+	if !ok {
+		return "", "", "", "", fmt.Errorf("CaptchaProfile Error: The [%s] profile does not exist", name)
+	}
+
+	dots, b64, tb64, key, err := p.Captcha.Generate()
+	if err != nil {
+		return "", "", "", "", err
+	}
+	//加入缓存
+	writeCache(cacheRecord{
+		Profile:   p.Name,
+		ExpiresAt: time.Now().Add(p.TTL).Unix(),
+		Padding:   DefaultCheckPadding,
+		Dots:      dots,
+	}, key)
+	return dots, b64, tb64, key, nil
+}
+
+// VerifyCaptchaWithProfile is a function
+/**
+ * @Description: 校验验证码，KEY 只能在签发它的配置下通过校验，过期的验证码校验失败
+ * @param name
+ * @param key
+ * @param dots
+ * @return bool
+ */
+func VerifyCaptchaWithProfile(name, key, dots string) bool {
+	if name == "" {
+		name = DefaultProfile
+	}
+	if dots == "" || key == "" {
+		return false
+	}
+
+	cacheData := readCache(key)
+	if cacheData == "" {
+		return false
+	}
+
+	var record cacheRecord
+	if err := json.Unmarshal([]byte(cacheData), &record); err != nil {
+		return false
+	}
+	if record.Profile != name || time.Now().Unix() > record.ExpiresAt {
+		return false
+	}
+
+	return checkCacheDots(record.Dots, dots, record.Padding)
+}
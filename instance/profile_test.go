@@ -0,0 +1,59 @@
+package instance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyCaptchaWithProfile(t *testing.T) {
+	cc := newTestCaptcha(t)
+	registerTestProfile(t, "test-a", cc)
+	registerTestProfile(t, "test-b", cc)
+
+	dots, _, _, key, err := MakeCaptchaWithProfile("test-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyCaptchaWithProfile("test-a", key, clickDots(dots.(map[int]CharDot))) {
+		t.Fatal("VerifyCaptchaWithProfile() rejected the issuing profile")
+	}
+
+	// KEY 只能在签发它的配置下通过校验
+	dots, _, _, key, err = MakeCaptchaWithProfile("test-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyCaptchaWithProfile("test-b", key, clickDots(dots.(map[int]CharDot))) {
+		t.Fatal("VerifyCaptchaWithProfile() accepted a key issued by another profile")
+	}
+
+	if _, _, _, _, err := MakeCaptchaWithProfile("test-missing"); err == nil {
+		t.Fatal("MakeCaptchaWithProfile() with an unknown profile did not fail")
+	}
+}
+
+func TestVerifyCaptchaWithProfileExpired(t *testing.T) {
+	registerTestProfile(t, "test-a", newTestCaptcha(t))
+
+	dots := map[int]CharDot{0: {Index: 0, Dx: 10, Dy: 60, Width: 40, Height: 40}}
+	for _, tc := range []struct {
+		key       string
+		expiresAt time.Time
+		want      bool
+	}{
+		{"profile-valid", time.Now().Add(time.Minute), true},
+		{"profile-expired", time.Now().Add(-time.Second), false},
+	} {
+		if err := writeCache(cacheRecord{
+			Profile:   "test-a",
+			ExpiresAt: tc.expiresAt.Unix(),
+			Padding:   DefaultCheckPadding,
+			Dots:      dots,
+		}, tc.key); err != nil {
+			t.Fatal(err)
+		}
+		if got := VerifyCaptchaWithProfile("test-a", tc.key, clickDots(dots)); got != tc.want {
+			t.Errorf("VerifyCaptchaWithProfile(%s) = %v, want %v", tc.key, got, tc.want)
+		}
+	}
+}
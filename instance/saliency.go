@@ -0,0 +1,127 @@
+package instance
+
+import (
+	"image"
+	"math"
+)
+
+/**
+ * @Description: 主图文本排布方式
+ */
+const (
+	// 随机排布
+	PlacementRandom = iota
+	// 避开背景中细节多或与文本颜色相近的区域
+	PlacementSaliency
+)
+
+// 显著性排布时每个文本比较的候选位置数量
+const saliencyCandidates = 24
+
+/**
+ * @Description: 背景裁剪区域的干扰度，边缘越密集、亮度越接近文本颜色，干扰度越高
+ */
+type saliencyMap struct {
+	width, height int
+	// 干扰度的积分图，尺寸为 (width+1)*(height+1)
+	sum []float64
+}
+
+/**
+ * @Description: 计算背景裁剪区域的干扰度
+ * @param img			背景图
+ * @param cut			裁剪位置，即 rangCutImage 的结果
+ * @param size			主图尺寸
+ * @param textColors	文本颜色，用于计算亮度的相近程度
+ * @return *saliencyMap
+ */
+func newSaliencyMap(img image.Image, cut image.Point, size Size, textColors []string) *saliencyMap {
+	w, h := size.Width, size.Height
+	lum := luminancePlane(img, cut, w, h)
+
+	// 文本颜色的平均亮度
+	textLum := 0.0
+	for _, hex := range textColors {
+		co, _ := ParseHexColor(hex)
+		textLum += 0.299*float64(co.R) + 0.587*float64(co.G) + 0.114*float64(co.B)
+	}
+	if len(textColors) > 0 {
+		textLum /= float64(len(textColors))
+	}
+
+	at := func(x, y int) float64 {
+		x = min(max(x, 0), w-1)
+		y = min(max(y, 0), h-1)
+		return lum[y*w+x]
+	}
+
+	stride := w + 1
+	sum := make([]float64, stride*(h+1))
+	for y := 0; y < h; y++ {
+		row := 0.0
+		for x := 0; x < w; x++ {
+			// 中心差分近似梯度，作为边缘密度
+			edge := (math.Abs(at(x+1, y)-at(x-1, y)) + math.Abs(at(x, y+1)-at(x, y-1))) / 510
+			similar := 1 - math.Abs(lum[y*w+x]-textLum)/255
+			row += edge*0.6 + similar*0.4
+			sum[(y+1)*stride+x+1] = sum[y*stride+x+1] + row
+		}
+	}
+
+	return &saliencyMap{width: w, height: h, sum: sum}
+}
+
+/**
+ * @Description: 区域内的平均干扰度
+ * @receiver s
+ * @param r
+ * @return float64
+ */
+func (s *saliencyMap) cost(r image.Rectangle) float64 {
+	r = r.Intersect(image.Rect(0, 0, s.width, s.height))
+	if r.Empty() {
+		return math.MaxFloat64
+	}
+
+	stride := s.width + 1
+	total := s.sum[r.Max.Y*stride+r.Max.X] - s.sum[r.Min.Y*stride+r.Max.X] -
+		s.sum[r.Max.Y*stride+r.Min.X] + s.sum[r.Min.Y*stride+r.Min.X]
+	return total / float64(r.Dx()*r.Dy())
+}
+
+/**
+ * @Description: 读取裁剪区域的亮度，超出背景图的部分按黑色处理
+ * @param img
+ * @param cut
+ * @param w
+ * @param h
+ * @return []float64
+ */
+func luminancePlane(img image.Image, cut image.Point, w, h int) []float64 {
+	lum := make([]float64, w*h)
+	b := img.Bounds()
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + cut.Y + y
+		if sy >= b.Max.Y {
+			break
+		}
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + cut.X + x
+			if sx >= b.Max.X {
+				break
+			}
+
+			switch m := img.(type) {
+			case *image.YCbCr:
+				lum[y*w+x] = float64(m.Y[m.YOffset(sx, sy)])
+			case *image.RGBA:
+				i := m.PixOffset(sx, sy)
+				lum[y*w+x] = 0.299*float64(m.Pix[i]) + 0.587*float64(m.Pix[i+1]) + 0.114*float64(m.Pix[i+2])
+			default:
+				r, g, bl, _ := m.At(sx, sy).RGBA()
+				lum[y*w+x] = (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)) / 257
+			}
+		}
+	}
+	return lum
+}
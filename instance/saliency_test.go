@@ -0,0 +1,78 @@
+package instance
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// 左半部分为细密的黑白格，右半部分为纯黑
+func testSaliencyBackground() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 240))
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{A: 0xff}
+			if x < 150 && (x/2+y/2)%2 == 0 {
+				c = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestSaliencyCost(t *testing.T) {
+	size := Size{Width: 300, Height: 240}
+	sal := newSaliencyMap(testSaliencyBackground(), image.Point{}, size, []string{"#ffffff"})
+
+	busy := sal.cost(image.Rect(20, 20, 120, 200))
+	clean := sal.cost(image.Rect(180, 20, 280, 200))
+	if busy <= clean*10 {
+		t.Fatalf("cost(busy) = %v, cost(clean) = %v, want the busy half to cost far more", busy, clean)
+	}
+	if sal.cost(image.Rect(400, 400, 410, 410)) < busy {
+		t.Fatal("cost() outside the image is not the highest")
+	}
+}
+
+func TestPlaceDotsSaliency(t *testing.T) {
+	cc := newTestCaptcha(t)
+	cfg := cc.Config()
+	size := Size{Width: 300, Height: 240}
+	sal := newSaliencyMap(testSaliencyBackground(), image.Point{}, size, []string{"#ffffff"})
+	busy := sal.cost(image.Rect(20, 20, 120, 200))
+
+	// 候选位置随机选取，只要求绝大多数文本落在干扰度低的位置
+	total, noisy := 0, 0
+	for n := 0; n < 20; n++ {
+		answers, err := cc.placeDots(&cfg, size, testLayoutDots(3, 30), sal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dot := range answers {
+			rect := image.Rect(dot.Dx, dot.Dy-dot.Height, dot.Dx+dot.Width, dot.Dy)
+			total++
+			if sal.cost(rect) > busy/4 {
+				noisy++
+			}
+		}
+	}
+	if noisy*10 > total {
+		t.Fatalf("%d of %d boxes placed over the busy half, want them over the clean half", noisy, total)
+	}
+}
+
+func TestTextPlacementOption(t *testing.T) {
+	cc := newTestCaptcha(t, WithTextPlacement(PlacementSaliency))
+	if _, _, _, _, err := cc.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	cfg := cc.Config()
+	cfg.TextPlacement = 9
+	if err := cc.SetConfig(cfg); err == nil {
+		t.Fatal("SetConfig() accepted an unknown TextPlacement")
+	}
+	if _, err := NewCaptcha(WithTextPlacement(9)); err == nil {
+		t.Fatal("WithTextPlacement() accepted an unknown placement")
+	}
+}
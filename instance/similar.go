@@ -0,0 +1,207 @@
+package instance
+
+import (
+	"slices"
+	"sync"
+)
+
+// 内置的形近字分组，同一组的字符不会同时出现在一个验证码中
+var similarGroups = [][]string{
+	// 简体及繁体中文
+	{"未", "末", "朱", "木", "本", "术"},
+	{"己", "已", "巳"},
+	{"人", "入", "八"},
+	{"日", "曰", "目", "且"},
+	{"土", "士", "王", "玉", "主"},
+	{"戊", "戌", "戍", "戎", "成"},
+	{"千", "干", "于", "午", "牛"},
+	{"天", "夭", "夫", "大", "太", "犬"},
+	{"白", "自", "百"},
+	{"刀", "力", "刁", "刃", "万", "方"},
+	{"贝", "见"},
+	{"田", "由", "甲", "申", "电"},
+	{"免", "兔"},
+	{"乌", "鸟", "烏", "鳥"},
+	{"候", "侯"},
+	{"拔", "拨"},
+	{"体", "休"},
+	{"折", "拆", "析"},
+	{"今", "令"},
+	{"子", "孑", "孓"},
+	{"冶", "治"},
+	{"汩", "汨"},
+	{"崇", "祟"},
+	{"戴", "载"},
+	{"辨", "辩", "辫", "瓣"},
+	{"问", "间", "問", "間"},
+	{"延", "廷"},
+	{"母", "毋", "毌"},
+	{"儿", "几", "九", "丸"},
+	{"办", "为"},
+	{"厂", "广"},
+	{"书", "节"},
+	{"晴", "睛", "情", "清", "请", "精"},
+	{"旦", "但", "担"},
+	{"书", "書", "晝", "畫"},
+	{"析", "柝"},
+	// 拉丁字母及数字
+	{"O", "o", "0", "Q", "D"},
+	{"I", "l", "1", "i", "j", "J", "|"},
+	{"S", "s", "5"},
+	{"Z", "z", "2"},
+	{"B", "8", "3"},
+	{"G", "6", "b"},
+	{"g", "9", "q"},
+	{"C", "c"},
+	{"U", "u", "V", "v"},
+	{"W", "w"},
+	{"X", "x"},
+	{"K", "k"},
+	{"P", "p"},
+	{"Y", "y"},
+	{"n", "h"},
+	{"7", "T"},
+	// 日文假名
+	{"へ", "ヘ"},
+	{"り", "リ"},
+	{"カ", "力", "か"},
+	{"エ", "工"},
+	{"ロ", "口"},
+	{"ニ", "二"},
+	{"ハ", "八"},
+	{"タ", "夕"},
+	{"ト", "卜"},
+	{"ソ", "ン", "ノ"},
+	{"シ", "ツ"},
+	{"チ", "千", "テ"},
+	{"ミ", "三"},
+	{"き", "さ", "ち"},
+	{"る", "ろ"},
+	{"ぬ", "め"},
+	{"は", "ほ", "け"},
+	{"わ", "れ", "ね"},
+	{"ク", "ケ", "タ"},
+	{"ウ", "ワ", "フ"},
+	{"コ", "ユ"},
+	{"ヌ", "ス", "マ", "ム"},
+	{"ル", "レ"},
+}
+
+var (
+	// 字符所在的分组，一个字符可以属于多个分组
+	_similar   = make(map[string][]int)
+	_groupNum  int
+	_similarMu sync.RWMutex
+)
+
+func init() {
+	for _, group := range similarGroups {
+		RegisterSimilarGroup(group...)
+	}
+}
+
+// RegisterSimilarGroup is a function
+/**
+ * @Description: 注册一组形近字，同一组的字符不会同时出现在一个验证码中，一个字符可以属于多个分组
+ * @param chars
+ */
+func RegisterSimilarGroup(chars ...string) {
+	if len(chars) < 2 {
+		return
+	}
+
+	_similarMu.Lock()
+	defer _similarMu.Unlock()
+	id := _groupNum
+	_groupNum++
+	for _, char := range chars {
+		if slices.Contains(_similar[char], id) {
+			continue
+		}
+		_similar[char] = append(_similar[char], id)
+	}
+}
+
+// IsSimilarChar is a function
+/**
+ * @Description: 两个字符是否属于同一组形近字
+ * @param a
+ * @param b
+ * @return bool
+ */
+func IsSimilarChar(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	_similarMu.RLock()
+	defer _similarMu.RUnlock()
+	for _, g := range _similar[a] {
+		if slices.Contains(_similar[b], g) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @Description: 字符是否与集合中任意字符相似
+ * @param chars
+ * @param char
+ * @return bool
+ */
+func hasSimilarChar(chars []string, char string) bool {
+	for _, c := range chars {
+		if IsSimilarChar(c, char) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @Description: 互不相似的字符组数，有共同形近字分组的字符合并为一组，不属于任何分组的字符各为一组。
+ * 不同组的字符一定互不相似，随机选取时至少能选出这么多互不相似的字符
+ * @param chars
+ * @return int
+ */
+func dissimilarCount(chars []string) int {
+	_similarMu.RLock()
+	defer _similarMu.RUnlock()
+
+	// 按字符所在的分组合并分组
+	parent := make(map[int]int)
+	var find func(g int) int
+	find = func(g int) int {
+		if p, ok := parent[g]; ok && p != g {
+			parent[g] = find(p)
+			return parent[g]
+		}
+		parent[g] = g
+		return g
+	}
+
+	count := 0
+	seen := make(map[string]bool, len(chars))
+	for _, char := range chars {
+		if seen[char] {
+			continue
+		}
+		seen[char] = true
+		groups := _similar[char]
+		if len(groups) == 0 {
+			count++
+			continue
+		}
+		root := find(groups[0])
+		for _, g := range groups[1:] {
+			parent[find(g)] = root
+		}
+	}
+
+	roots := make(map[int]bool)
+	for g := range parent {
+		roots[find(g)] = true
+	}
+	return count + len(roots)
+}
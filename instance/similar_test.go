@@ -0,0 +1,100 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSimilarChar(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"未", "末", true},
+		{"己", "巳", true},
+		{"鸟", "鳥", true},
+		{"未", "己", false},
+		{"好", "好", true},
+	} {
+		if got := IsSimilarChar(tc.a, tc.b); got != tc.want {
+			t.Errorf("IsSimilarChar(%s, %s) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+
+	RegisterSimilarGroup("测", "侧", "恻")
+	if !IsSimilarChar("侧", "恻") || IsSimilarChar("测", "未") {
+		t.Fatal("RegisterSimilarGroup() did not add the group")
+	}
+}
+
+func TestGenRandCharAvoidsSimilar(t *testing.T) {
+	cc := newTestCaptcha(t)
+	chars := []string{"未", "末", "己", "已", "巳", "人", "入", "八", "好"}
+	for n := 0; n < 200; n++ {
+		s := strings.Split(cc.genRandChar(chars, 4), ":")
+		if len(s) != 4 {
+			t.Fatalf("genRandChar() = %v, want 4 chars", s)
+		}
+		for i := range s {
+			for j := i + 1; j < len(s); j++ {
+				if IsSimilarChar(s[i], s[j]) {
+					t.Fatalf("genRandChar() = %v has similar chars", s)
+				}
+			}
+		}
+	}
+}
+
+func TestRangeCheckDotsAvoidsSimilar(t *testing.T) {
+	cc := newTestCaptcha(t)
+	dots := map[int]CharDot{}
+	for i, c := range []string{"未", "末", "己", "已", "好"} {
+		dots[i] = CharDot{Text: c}
+	}
+	cfg := DefaultConfig()
+	cfg.RangCheckTextLen = RangeVal{3, 3}
+	for n := 0; n < 200; n++ {
+		chk, str := cc.rangeCheckDots(&cfg, dots)
+		s := strings.Split(str, ":")
+		if len(chk) != 3 || IsSimilarChar(s[0], s[1]) || IsSimilarChar(s[0], s[2]) || IsSimilarChar(s[1], s[2]) {
+			t.Fatalf("rangeCheckDots() = %v", str)
+		}
+		// 验证点按点击顺序编号
+		for i := 0; i < 3; i++ {
+			if chk[i].Index != i || chk[i].Text != s[i] {
+				t.Fatalf("check dot %d = %+v", i, chk[i])
+			}
+		}
+	}
+}
+
+func TestGenRandCharNoBackfill(t *testing.T) {
+	cc := newTestCaptcha(t)
+	chars := []string{"未", "末", "己", "已", "巳", "人", "入", "八", "好"}
+	if n := dissimilarCount(chars); n != 4 {
+		t.Fatalf("dissimilarCount() = %d, want 4", n)
+	}
+	// 互不相似的字符不足时返回的字符变少，不会补入形近字
+	for n := 0; n < 50; n++ {
+		if s := strings.Split(cc.genRandChar(chars, 7), ":"); len(s) != 4 {
+			t.Fatalf("genRandChar() = %v, want 4 dissimilar chars", s)
+		}
+	}
+}
+
+func TestDissimilarCount(t *testing.T) {
+	if n := dissimilarCount([]string{"B", "8", "3", "S", "5", "A", "A"}); n != 3 {
+		t.Errorf("dissimilarCount() = %d, want 3", n)
+	}
+
+	digits, err := CharsetChars(CharsetDigits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCaptcha(WithRangChars(digits), WithTextRangLen(RangeVal{4, 8})); err == nil || !strings.Contains(err.Error(), "dissimilar char groups (7)") {
+		t.Errorf("NewCaptcha() = %v, want a dissimilar char groups error", err)
+	}
+	if _, err := NewCaptcha(WithRangChars(digits), WithTextRangLen(RangeVal{4, 7})); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,201 @@
+package instance
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goravel/framework/contracts/filesystem"
+)
+
+// StorageFS is a type
+/**
+ * @Description: 将 Goravel Storage 磁盘适配为 fs.FS，用于 SetBackgroundFS、SetFontFS
+ * @Example: instance.NewStorageFS(facades.Storage().Disk("local"))
+ */
+type StorageFS struct {
+	driver filesystem.Driver
+}
+
+// NewStorageFS is a function
+/**
+ * @Description: 创建 Storage 磁盘适配器
+ * @param driver
+ * @return *StorageFS
+ */
+func NewStorageFS(driver filesystem.Driver) *StorageFS {
+	return &StorageFS{driver: driver}
+}
+
+// Open is a function
+/**
+ * @Description: 实现 fs.FS
+ * @receiver s
+ * @param name
+ * @return fs.File
+ * @return error
+ */
+func (s *StorageFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name != "." {
+		if !s.driver.Exists(name) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		if content, err := s.driver.GetBytes(name); err == nil {
+			info := s.fileInfo(name)
+			info.size = int64(len(content))
+			return &storageFile{info: info, reader: bytes.NewReader(content)}, nil
+		}
+	}
+
+	entries, err := s.ReadDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &storageDir{info: &storageFileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// ReadFile is a function
+/**
+ * @Description: 实现 fs.ReadFileFS
+ * @receiver s
+ * @param name
+ * @return []byte
+ * @return error
+ */
+func (s *StorageFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	content, err := s.driver.GetBytes(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+	return content, nil
+}
+
+// ReadDir is a function
+/**
+ * @Description: 实现 fs.ReadDirFS，按文件名排序
+ * @receiver s
+ * @param name
+ * @return []fs.DirEntry
+ * @return error
+ */
+func (s *StorageFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	files, err := s.driver.Files(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	dirs, err := s.driver.Directories(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	var entries []fs.DirEntry
+	for _, file := range files {
+		entries = append(entries, fs.FileInfoToDirEntry(s.fileInfo(path.Join(name, path.Base(file)))))
+	}
+	for _, dir := range dirs {
+		dir = strings.TrimRight(dir, "/\\")
+		entries = append(entries, fs.FileInfoToDirEntry(&storageFileInfo{name: path.Base(dir), dir: true}))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+/**
+ * @Description: 读取文件的大小及修改时间
+ * @receiver s
+ * @param name
+ * @return *storageFileInfo
+ */
+func (s *StorageFS) fileInfo(name string) *storageFileInfo {
+	info := &storageFileInfo{name: path.Base(name)}
+	if size, err := s.driver.Size(name); err == nil {
+		info.size = size
+	}
+	if modTime, err := s.driver.LastModified(name); err == nil {
+		info.modTime = modTime
+	}
+	return info
+}
+
+/**
+ * @Description: Storage 文件信息
+ */
+type storageFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *storageFileInfo) Name() string       { return i.name }
+func (i *storageFileInfo) Size() int64        { return i.size }
+func (i *storageFileInfo) ModTime() time.Time { return i.modTime }
+func (i *storageFileInfo) IsDir() bool        { return i.dir }
+func (i *storageFileInfo) Sys() any           { return nil }
+func (i *storageFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+/**
+ * @Description: Storage 文件，内容已读入内存
+ */
+type storageFile struct {
+	info   *storageFileInfo
+	reader *bytes.Reader
+}
+
+func (f *storageFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *storageFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *storageFile) Close() error               { return nil }
+
+/**
+ * @Description: Storage 目录
+ */
+type storageDir struct {
+	info    *storageFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *storageDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *storageDir) Close() error               { return nil }
+func (d *storageDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *storageDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,241 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/**
+ * @Description: 内置配色方案名称
+ */
+const (
+	// 默认配色，缩略图背景透明
+	ThemeDefault = "default"
+	// 深色配色，缩略图为深色底色及浅色文本
+	ThemeDark = "dark"
+	// 绿色弱友好配色，避免依赖红绿区分
+	ThemeDeuteranopia = "deuteranopia"
+	// 红色弱友好配色，避免依赖红绿区分且不使用偏暗的纯红
+	ThemeProtanopia = "protanopia"
+)
+
+// Theme is a type
+/**
+ * @Description: 命名配色方案，覆盖主图及缩略图的文本、阴影和背景颜色
+ */
+type Theme struct {
+	// 配色名称
+	Name string
+	// 主图文本颜色
+	FontColors []string
+	// 主图文本阴影颜色
+	TextShadowColor string
+	// 缩略图文本颜色
+	ThumbFontColors []string
+	// 缩略图干扰线及小圆点颜色
+	ThumbBgColors []string
+	// 缩略图底色，为空时透明
+	ThumbBgFillColor string
+}
+
+var (
+	_themes   = make(map[string]Theme)
+	_themesMu sync.RWMutex
+	// 客户端可以请求的配色
+	_allowedThemes = make(map[string]bool)
+)
+
+func init() {
+	_ = RegisterTheme(Theme{
+		Name:            ThemeDefault,
+		FontColors:      getDefaultTextColors(),
+		TextShadowColor: getDefaultTextShadowColor(),
+		ThumbFontColors: getDefaultThumbTextColors(),
+		ThumbBgColors:   getDefaultThumbTextColors(),
+	})
+	_ = RegisterTheme(Theme{
+		Name:             ThemeDark,
+		FontColors:       getDefaultTextColors(),
+		TextShadowColor:  "#000000",
+		ThumbFontColors:  []string{"#fde98e", "#60c1ff", "#fcb08e", "#fb88ff", "#b4fed4", "#cbfaa9"},
+		ThumbBgColors:    []string{"#4a5468", "#5a4a6e", "#46604f", "#6b5a3e"},
+		ThumbBgFillColor: "#1f2430",
+	})
+	// 以 Okabe-Ito 配色为基础，主图使用浅色，缩略图使用深色，颜色之间靠蓝黄及明暗区分
+	_ = RegisterTheme(Theme{
+		Name:            ThemeDeuteranopia,
+		FontColors:      []string{"#f0e442", "#56b4e9", "#e69f00", "#cc79a7", "#ffffff"},
+		TextShadowColor: "#101010",
+		ThumbFontColors: []string{"#0072b2", "#d55e00", "#1a1a1a", "#6a3d9a"},
+		ThumbBgColors:   []string{"#0072b2", "#d55e00", "#1a1a1a", "#6a3d9a"},
+	})
+	_ = RegisterTheme(Theme{
+		Name:            ThemeProtanopia,
+		FontColors:      []string{"#f0e442", "#56b4e9", "#9ad0f5", "#e69f00", "#ffffff"},
+		TextShadowColor: "#101010",
+		ThumbFontColors: []string{"#0072b2", "#1a1a1a", "#8a5a00", "#5d3a9b"},
+		ThumbBgColors:   []string{"#0072b2", "#1a1a1a", "#8a5a00", "#5d3a9b"},
+	})
+	_ = AllowThemes(ThemeDefault, ThemeDark, ThemeDeuteranopia, ThemeProtanopia)
+}
+
+// RegisterTheme is a function
+/**
+ * @Description: 注册配色方案，同名配色会被替换
+ * @param t
+ * @return error
+ */
+func RegisterTheme(t Theme) error {
+	if t.Name == "" {
+		return fmt.Errorf("Captcha Theme Error: The name must not be empty")
+	}
+	errs := []error{
+		checkColors("FontColors", t.FontColors),
+		checkColor("TextShadowColor", t.TextShadowColor),
+		checkColors("ThumbFontColors", t.ThumbFontColors),
+		checkColors("ThumbBgColors", t.ThumbBgColors),
+	}
+	if t.ThumbBgFillColor != "" {
+		errs = append(errs, checkColor("ThumbBgFillColor", t.ThumbBgFillColor))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	_themesMu.Lock()
+	defer _themesMu.Unlock()
+	_themes[t.Name] = Theme{
+		Name:             t.Name,
+		FontColors:       append([]string(nil), t.FontColors...),
+		TextShadowColor:  t.TextShadowColor,
+		ThumbFontColors:  append([]string(nil), t.ThumbFontColors...),
+		ThumbBgColors:    append([]string(nil), t.ThumbBgColors...),
+		ThumbBgFillColor: t.ThumbBgFillColor,
+	}
+	return nil
+}
+
+// GetTheme is a function
+/**
+ * @Description: 获取配色方案
+ * @param name
+ * @return Theme
+ * @return bool
+ */
+func GetTheme(name string) (Theme, bool) {
+	_themesMu.RLock()
+	defer _themesMu.RUnlock()
+
+	t, ok := _themes[name]
+	return t, ok
+}
+
+// AllowThemes is a function
+/**
+ * @Description: 设置客户端可以请求的配色，替换原有的允许列表
+ * @param names
+ * @return error	配色未注册
+ */
+func AllowThemes(names ...string) error {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := GetTheme(name); !ok {
+			return fmt.Errorf("Captcha Theme Error: The [%s] theme does not exist", name)
+		}
+		allowed[name] = true
+	}
+
+	_themesMu.Lock()
+	defer _themesMu.Unlock()
+	_allowedThemes = allowed
+	return nil
+}
+
+// AllowedThemes is a function
+/**
+ * @Description: 客户端可以请求的配色，按名称排序
+ * @return []string
+ */
+func AllowedThemes() []string {
+	_themesMu.RLock()
+	defer _themesMu.RUnlock()
+
+	names := make([]string, 0, len(_allowedThemes))
+	for name := range _allowedThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsThemeAllowed is a function
+/**
+ * @Description: 配色是否在允许列表中
+ * @param name
+ * @return bool
+ */
+func IsThemeAllowed(name string) bool {
+	_themesMu.RLock()
+	defer _themesMu.RUnlock()
+
+	_, registered := _themes[name]
+	return registered && _allowedThemes[name]
+}
+
+// MakeThemedCaptcha is a function
+/**
+ * @Description: 使用命名配置及客户端请求的配色生成验证码，配色须在允许列表中，启用自适应难度时按来源调整难度
+ * @param name
+ * @param identity	来源标识，IP 或会话ID
+ * @param theme		配色名称，为空时使用实例配色
+ * @return interface{}	位置信息
+ * @return string		主图Base64
+ * @return string		缩略图Base64
+ * @return string		验证码KEY
+ * @return error
+ */
+func MakeThemedCaptcha(name, identity, theme string) (interface{}, string, string, string, error) {
+	if theme != "" && !IsThemeAllowed(theme) {
+		return "", "", "", "", fmt.Errorf("Captcha Theme Error: The [%s] theme is not allowed", theme)
+	}
+	return makeCaptcha(name, identity, theme)
+}
+
+// WithTheme is a function
+/**
+ * @Description: 使用内置或已注册的配色方案，覆盖主图及缩略图的文本、阴影和背景颜色
+ * @param name
+ * @return Option
+ */
+func WithTheme(name string) Option {
+	return func(c *Config) error {
+		t, ok := GetTheme(name)
+		if !ok {
+			return fmt.Errorf("Captcha Theme Error: The [%s] theme does not exist", name)
+		}
+		c.RangFontColors = append([]string(nil), t.FontColors...)
+		c.TextShadowColor = t.TextShadowColor
+		c.RangThumbFontColors = append([]string(nil), t.ThumbFontColors...)
+		c.RangThumbBgColors = append([]string(nil), t.ThumbBgColors...)
+		c.ThumbBgFillColor = t.ThumbBgFillColor
+		return nil
+	}
+}
+
+// SetTheme is a function
+/**
+ * @Description: 设置配色方案
+ * @receiver cc
+ * @param name
+ * @return error
+ */
+func (cc *Captcha) SetTheme(name string) error {
+	return cc.apply(WithTheme(name))
+}
@@ -0,0 +1,63 @@
+package instance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAllowThemes(t *testing.T) {
+	before := AllowedThemes()
+	t.Cleanup(func() { _ = AllowThemes(before...) })
+
+	if err := AllowThemes(ThemeDark, "missing"); err == nil {
+		t.Fatal("AllowThemes() with an unregistered theme did not fail")
+	}
+	if !slices.Equal(AllowedThemes(), before) {
+		t.Fatal("a rejected AllowThemes() changed the allowlist")
+	}
+
+	if err := AllowThemes(ThemeDark); err != nil {
+		t.Fatal(err)
+	}
+	if !IsThemeAllowed(ThemeDark) || IsThemeAllowed(ThemeDefault) || IsThemeAllowed("missing") {
+		t.Fatalf("AllowedThemes() = %v, want only %s", AllowedThemes(), ThemeDark)
+	}
+}
+
+func TestMakeThemedCaptcha(t *testing.T) {
+	before := AllowedThemes()
+	t.Cleanup(func() { _ = AllowThemes(before...) })
+	registerTestProfile(t, "test-theme", newTestCaptcha(t))
+	if err := AllowThemes(ThemeDark); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不在允许列表中的配色被拒绝
+	if _, _, _, _, err := MakeThemedCaptcha("test-theme", "", ThemeDeuteranopia); err == nil {
+		t.Fatal("MakeThemedCaptcha() accepted a theme outside the allowlist")
+	}
+	dots, _, _, key, err := MakeThemedCaptcha("test-theme", "", ThemeDark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyCaptchaWithProfile("test-theme", key, clickDots(dots.(map[int]CharDot))) {
+		t.Fatal("a themed captcha did not verify under its profile")
+	}
+}
+
+func TestWithTheme(t *testing.T) {
+	cc := newTestCaptcha(t, WithTheme(ThemeDark))
+	dark, _ := GetTheme(ThemeDark)
+	cfg := cc.Config()
+	if !slices.Equal(cfg.RangThumbFontColors, dark.ThumbFontColors) || cfg.ThumbBgFillColor != dark.ThumbBgFillColor {
+		t.Fatal("WithTheme() did not apply the theme colors")
+	}
+	if err := cc.SetTheme("missing"); err == nil {
+		t.Fatal("SetTheme() with an unknown theme did not fail")
+	}
+
+	if err := RegisterTheme(Theme{Name: "test-bad", FontColors: []string{"red"}, TextShadowColor: "#000000",
+		ThumbFontColors: []string{"#000000"}, ThumbBgColors: []string{"#000000"}}); err == nil {
+		t.Fatal("RegisterTheme() accepted an invalid color")
+	}
+}
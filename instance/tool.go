@@ -12,7 +12,8 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
-	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
 )
 
 /**
@@ -181,6 +182,24 @@ func RandInt(min, max int) int {
 	return int(int64(min) + result.Int64())
 }
 
+// RandPerm is a function
+/**
+ * @Description: 生成[0, n)的安全随机排列，不依赖全局 math/rand 的种子
+ * @param n
+ * @return []int
+ */
+func RandPerm(n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := RandInt(0, i)
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
 // RandFloat is a function
 /**
  * @Description: 随机浮点数
@@ -209,10 +228,20 @@ func IsChineseChar(str string) bool {
 
 // LenChineseChar is a function
 /**
- * @Description: 计算中文及字母长度，例如：“你好hello” = 7
+ * @Description: 按字素簇计算中文及字母长度，多个码点组成的字符计为 1，例如：“你好hello” = 7，“e\u0301” = 1
  * @param str
  * @return int
  */
 func LenChineseChar(str string) int {
-	return utf8.RuneCountInString(str)
+	return uniseg.GraphemeClusterCount(str)
+}
+
+// CharWidth is a function
+/**
+ * @Description: 按东亚宽度计算显示宽度，中日韩文字及全角字符为 2，拉丁字母等为 1，例如：“한” = 2，“WM” = 2，“é” = 1
+ * @param str
+ * @return int
+ */
+func CharWidth(str string) int {
+	return uniseg.StringWidth(str)
 }
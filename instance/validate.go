@@ -0,0 +1,354 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 单个字符的最大显示宽度，一个中日韩文字或两个拉丁字母
+const maxCharWidth = 2
+
+/**
+ * @Description: 配置快照的校验结果，快照只读，只需校验一次
+ */
+type validation struct {
+	once sync.Once
+	err  error
+}
+
+/**
+ * @Description: 校验配置，作为实例快照的配置只在首次生成时校验一次，其他配置每次都校验
+ * @receiver c
+ * @return error
+ */
+func (c *Config) validateOnce() error {
+	if c.validation == nil {
+		return c.Validate()
+	}
+	c.validation.once.Do(func() {
+		c.validation.err = c.Validate()
+	})
+	return c.validation.err
+}
+
+// Validate is a function
+/**
+ * @Description: 检测当前配置是否完整和合法，一次返回所有问题
+ * @receiver cc
+ * @return error	多个错误通过 errors.Join 合并
+ */
+func (cc *Captcha) Validate() error {
+	return cc.snapshot().Validate()
+}
+
+// Validate is a function
+/**
+ * @Description: 检测配置，字段自身的合法性及字段之间的约束，一次返回所有问题
+ * @receiver cfg
+ * @return error	多个错误通过 errors.Join 合并
+ */
+func (cfg Config) Validate() error {
+	chars := cfg.RangChars
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(chars) == 0 {
+		add(fmt.Errorf("CaptchaConfig Error: The chars must not be empty"))
+	}
+	add(checkChars(chars))
+
+	add(checkRangeVal("RangTextLen", cfg.RangTextLen, 1))
+	add(checkRangeVal("RangCheckTextLen", cfg.RangCheckTextLen, 1))
+	add(checkRangeVal("RangFontSize", cfg.RangFontSize, 1))
+	add(checkRangeVal("RangCheckFontSize", cfg.RangCheckFontSize, 1))
+	add(checkAngles(cfg.RangTexAnglePos))
+	add(checkSize("ImageSize", cfg.ImageSize))
+	add(checkSize("ThumbnailSize", cfg.ThumbnailSize))
+	add(checkColors("RangFontColors", cfg.RangFontColors))
+	add(checkColors("RangThumbFontColors", cfg.RangThumbFontColors))
+	add(checkColors("RangThumbBgColors", cfg.RangThumbBgColors))
+	add(checkColor("TextShadowColor", cfg.TextShadowColor))
+	add(checkQuality(cfg.ImageQuality))
+	add(checkDistort("ImageFontDistort", cfg.ImageFontDistort))
+	add(checkDistort("ThumbBgDistort", cfg.ThumbBgDistort))
+	add(checkDistort("ThumbFontDistort", cfg.ThumbFontDistort))
+	add(checkAlpha(cfg.ImageFontAlpha))
+
+	if cfg.TextMinGap < 0 {
+		add(fmt.Errorf("CaptchaConfig Error: TextMinGap must be greater than or equal to 0"))
+	}
+	if cfg.TextEdgeMargin < 0 {
+		add(fmt.Errorf("CaptchaConfig Error: TextEdgeMargin must be greater than or equal to 0"))
+	}
+	add(checkPlacement(cfg.TextPlacement))
+	add(checkContrast(cfg.TextContrast))
+	if cfg.ThumbBgFillColor != "" {
+		add(checkColor("ThumbBgFillColor", cfg.ThumbBgFillColor))
+	}
+	add(checkInterferenceNum("ImageWaveLineNum", cfg.ImageWaveLineNum))
+	add(checkInterferenceNum("ImageArcNum", cfg.ImageArcNum))
+	add(checkInterferenceNum("ImageNoiseDotNum", cfg.ImageNoiseDotNum))
+	add(checkInterferenceNum("ImageDecoyStrokeNum", cfg.ImageDecoyStrokeNum))
+	add(checkInterferenceAlpha(cfg.ImageInterferenceAlpha))
+	add(checkAugment(cfg.BackgroundAugment))
+	if cfg.FontDPI <= 0 {
+		add(fmt.Errorf("CaptchaConfig Error: FontDPI must be greater than 0"))
+	}
+	if len(cfg.RangFont) == 0 {
+		add(fmt.Errorf("CaptchaConfig Error: RangFont must not be empty"))
+	}
+	if err := checkTextMode(cfg.TextMode); err != nil {
+		add(err)
+	} else {
+		add(checkPhrases(&cfg))
+		add(checkIcons(&cfg))
+	}
+	if err := checkCoverageMode(cfg.GlyphCoverage); err != nil {
+		add(err)
+	} else {
+		add(checkGlyphCoverage(&cfg))
+	}
+
+	// 验证颜色总和是否超出255个
+	if len(cfg.RangFontColors) >= 255 {
+		add(fmt.Errorf("CaptchaConfig Error: len(RangFontColors) must be less than 255"))
+	}
+	if len(cfg.RangThumbFontColors)+len(cfg.RangThumbBgColors) >= 255 {
+		add(fmt.Errorf("CaptchaConfig Error: len(RangThumbFontColors + RangThumbBgColors) must be less than 255"))
+	}
+
+	// 验证文本数量必须都能从随机字符串中选出
+	if cfg.RangCheckTextLen.Max > cfg.RangTextLen.Min {
+		add(fmt.Errorf("CaptchaConfig Error: RangCheckTextLen.Max (%d) must be less than or equal to RangTextLen.Min (%d)", cfg.RangCheckTextLen.Max, cfg.RangTextLen.Min))
+	}
+	// 随机字符串不能超出字符集合，否则无法生成不重复的字符
+	if len(chars) > 0 && cfg.RangTextLen.Max > len(chars) {
+		add(fmt.Errorf("CaptchaConfig Error: RangTextLen.Max (%d) must be less than or equal to len(chars) (%d)", cfg.RangTextLen.Max, len(chars)))
+	} else if n := dissimilarCount(chars); len(chars) > 0 && cfg.TextMode != TextModeIcon && cfg.RangTextLen.Max > n {
+		// 同一个验证码中不会出现形近字，字符集合需要有足够多组互不相似的字符
+		add(fmt.Errorf("CaptchaConfig Error: RangTextLen.Max (%d) must be less than or equal to the number of dissimilar char groups (%d)", cfg.RangTextLen.Max, n))
+	}
+	if cfg.RangFontSize.Max > cfg.ImageSize.Height {
+		add(fmt.Errorf("CaptchaConfig Error: RangFontSize.Max (%d) must be less than or equal to ImageSize.Height (%d)", cfg.RangFontSize.Max, cfg.ImageSize.Height))
+	}
+	if cfg.RangCheckFontSize.Max > cfg.ThumbnailSize.Height {
+		add(fmt.Errorf("CaptchaConfig Error: RangCheckFontSize.Max (%d) must be less than or equal to ThumbnailSize.Height (%d)", cfg.RangCheckFontSize.Max, cfg.ThumbnailSize.Height))
+	}
+	// 主图需要能互不重叠地放下最多的文本，按未旋转的最大文本估算
+	if box := cfg.RangFontSize.Max + 4 + cfg.TextMinGap; cfg.RangTextLen.Max*box*box > (cfg.ImageSize.Width-2*cfg.TextEdgeMargin+cfg.TextMinGap)*(cfg.ImageSize.Height-2*cfg.TextEdgeMargin+cfg.TextMinGap) {
+		add(fmt.Errorf("CaptchaConfig Error: ImageSize (%dx%d) is too small to place %d chars of size %d without overlap", cfg.ImageSize.Width, cfg.ImageSize.Height, cfg.RangTextLen.Max, cfg.RangFontSize.Max))
+	}
+	// 缩略图需要能并排放下最多的验证文本
+	if need := cfg.RangCheckTextLen.Max * cfg.RangCheckFontSize.Min; need > cfg.ThumbnailSize.Width {
+		add(fmt.Errorf("CaptchaConfig Error: ThumbnailSize.Width (%d) is too small for %d check chars of size %d", cfg.ThumbnailSize.Width, cfg.RangCheckTextLen.Max, cfg.RangCheckFontSize.Min))
+	}
+
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 检测字符集合，每个字符的显示宽度不能超出 maxCharWidth，即一个中日韩文字或两个拉丁字母，超出会影响位置的验证
+ * @param chars
+ * @return error
+ */
+func checkChars(chars []string) error {
+	var errs []error
+	for _, char := range chars {
+		if char == "" {
+			errs = append(errs, fmt.Errorf("Captcha SetRangChars Error: The char must not be empty"))
+			continue
+		}
+		if w := CharWidth(char); w == 0 {
+			errs = append(errs, fmt.Errorf("Captcha SetRangChars Error: The char %+q has no display width", char))
+		} else if w > maxCharWidth {
+			errs = append(errs, fmt.Errorf("Captcha SetRangChars Error: The display width of char [%s] is %d, must be less than or equal to %d", char, w, maxCharWidth))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 检测范围值
+ * @param name
+ * @param val
+ * @param min	允许的最小值
+ * @return error
+ */
+func checkRangeVal(name string, val RangeVal, min int) error {
+	if val.Min < min {
+		return fmt.Errorf("CaptchaConfig Error: %s.Min must be greater than or equal to %d", name, min)
+	}
+	if val.Min > val.Max {
+		return fmt.Errorf("CaptchaConfig Error: %s.Min must be less than or equal to %s.Max", name, name)
+	}
+	return nil
+}
+
+/**
+ * @Description: 检测文本角度范围
+ * @param pos
+ * @return error
+ */
+func checkAngles(pos []RangeVal) error {
+	if len(pos) == 0 {
+		return fmt.Errorf("CaptchaConfig Error: RangTexAnglePos must not be empty")
+	}
+
+	var errs []error
+	for i, angle := range pos {
+		if angle.Min < 0 || angle.Max > 360 || angle.Min > angle.Max {
+			errs = append(errs, fmt.Errorf("CaptchaConfig Error: RangTexAnglePos[%d] must be in 0-360 and Min less than or equal to Max", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 检测尺寸
+ * @param name
+ * @param size
+ * @return error
+ */
+func checkSize(name string, size Size) error {
+	if size.Width <= 0 || size.Height <= 0 {
+		return fmt.Errorf("CaptchaConfig Error: %s must be greater than 0", name)
+	}
+	return nil
+}
+
+/**
+ * @Description: 检测颜色集合
+ * @param name
+ * @param colors
+ * @return error
+ */
+func checkColors(name string, colors []string) error {
+	if len(colors) == 0 {
+		return fmt.Errorf("CaptchaConfig Error: %s must not be empty", name)
+	}
+
+	var errs []error
+	for _, co := range colors {
+		errs = append(errs, checkColor(name, co))
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 检测十六进制颜色，格式："#541245" 或 "#fff"
+ * @param name
+ * @param co
+ * @return error
+ */
+func checkColor(name string, co string) error {
+	if co == "" {
+		return fmt.Errorf("CaptchaConfig Error: %s color must not be empty", name)
+	}
+	if _, err := ParseHexColor(co); err != nil {
+		return fmt.Errorf("CaptchaConfig Error: %s color [%s] is invalid: %w", name, co, err)
+	}
+	return nil
+}
+
+/**
+ * @Description: 检测图片清晰度
+ * @param val
+ * @return error
+ */
+func checkQuality(val int) error {
+	if val == QualityCompressNone || (val <= QualityCompressLevel1 && val >= QualityCompressLevel5) {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: ImageQuality must be QualityCompressNone or between QualityCompressLevel5 and QualityCompressLevel1")
+}
+
+/**
+ * @Description: 检测扭曲程度
+ * @param name
+ * @param val
+ * @return error
+ */
+func checkDistort(name string, val int) error {
+	if val >= DistortNone && val <= DistortLevel5 {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: %s must be between DistortNone and DistortLevel5", name)
+}
+
+/**
+ * @Description: 检测透明度
+ * @param val
+ * @return error
+ */
+func checkAlpha(val float64) error {
+	if val > 0 && val <= 1 {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: ImageFontAlpha must be greater than 0 and less than or equal to 1")
+}
+
+/**
+ * @Description: 检测文本排布方式
+ * @param val
+ * @return error
+ */
+func checkPlacement(val int) error {
+	if val == PlacementRandom || val == PlacementSaliency {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: TextPlacement must be PlacementRandom or PlacementSaliency")
+}
+
+/**
+ * @Description: 验证对比度，0 为不调整，其余须在 1-21 之间
+ * @param val
+ * @return error
+ */
+func checkContrast(val float64) error {
+	if val == 0 || (val >= 1 && val <= 21) {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: TextContrast must be 0 or between 1 and 21")
+}
+
+/**
+ * @Description: 验证干扰数量
+ * @param name
+ * @param val
+ * @return error
+ */
+func checkInterferenceNum(name string, val int) error {
+	if val < 0 {
+		return fmt.Errorf("CaptchaConfig Error: %s must be greater than or equal to 0", name)
+	}
+	return nil
+}
+
+/**
+ * @Description: 验证干扰透明度
+ * @param val
+ * @return error
+ */
+func checkInterferenceAlpha(val float64) error {
+	if val > 0 && val <= 1 {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: ImageInterferenceAlpha must be greater than 0 and less than or equal to 1")
+}
+
+/**
+ * @Description: 验证背景增强强度
+ * @param val
+ * @return error
+ */
+func checkAugment(val float64) error {
+	if val >= 0 && val <= 1 {
+		return nil
+	}
+	return fmt.Errorf("CaptchaConfig Error: BackgroundAugment must be between 0 and 1")
+}
@@ -0,0 +1,113 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDefault(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReportsAll(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ImageQuality = 200
+	cfg.ImageFontDistort = 9
+	cfg.FontDPI = 0
+	cfg.RangFont = nil
+	cfg.RangCheckTextLen = RangeVal{7, 8}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil")
+	}
+	// 一次返回所有问题
+	for _, want := range []string{"ImageQuality", "ImageFontDistort", "FontDPI", "RangFont", "RangCheckTextLen.Max (8)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() does not report %s:\n%v", want, err)
+		}
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n < 5 {
+		t.Errorf("Validate() joined %d errors, want at least 5", n)
+	}
+}
+
+func TestSetterErrors(t *testing.T) {
+	cc := newTestCaptcha(t)
+	before := cc.Config()
+
+	if err := cc.SetImageQuality(200); err == nil {
+		t.Error("SetImageQuality(200) did not fail")
+	}
+	if err := cc.SetImageFontDistort(DistortLevel5 + 1); err == nil {
+		t.Error("SetImageFontDistort() out of range did not fail")
+	}
+	if err := cc.SetImageSize(Size{0, 240}); err == nil {
+		t.Error("SetImageSize() with zero width did not fail")
+	}
+	// 被拒绝的设置不修改配置
+	if !cc.Config().Equal(before) {
+		t.Error("a rejected setter replaced the config")
+	}
+
+	// 报告所有不合法的字符和文件
+	err := cc.SetRangChars([]string{"好", "", "abc", "你好"})
+	if err == nil || strings.Count(err.Error(), "\n") != 2 {
+		t.Errorf("SetRangChars() = %v, want 3 errors", err)
+	}
+	err = cc.SetFont([]string{"missing/a.ttf", "missing/b.ttf"})
+	if err == nil || !strings.Contains(err.Error(), "a.ttf") || !strings.Contains(err.Error(), "b.ttf") {
+		t.Errorf("SetFont() = %v, want both missing files", err)
+	}
+}
+
+func TestCharWidth(t *testing.T) {
+	tests := map[string]int{"한": 2, "中": 2, "WM": 2, "e\u0301": 1, "Ａ": 2, "A": 1}
+	for str, want := range tests {
+		if got := CharWidth(str); got != want {
+			t.Errorf("CharWidth(%+q) = %d, want %d", str, got, want)
+		}
+	}
+	if got := LenChineseChar("e\u0301한"); got != 2 {
+		t.Errorf("LenChineseChar() = %d, want 2 grapheme clusters", got)
+	}
+}
+
+func TestCheckChars(t *testing.T) {
+	if err := checkChars([]string{"한", "e\u0301", "中", "AB"}); err != nil {
+		t.Errorf("checkChars() = %v, want nil", err)
+	}
+	err := checkChars([]string{"中国", "ABC", "\u0301", ""})
+	if err == nil {
+		t.Fatal("checkChars() returned no error")
+	}
+	for _, want := range []string{"[中国] is 4", "[ABC] is 3", "no display width", "must not be empty"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("checkChars() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestValidateOnce(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.validation = &validation{}
+	if err := cfg.validateOnce(); err != nil {
+		t.Fatal(err)
+	}
+	// 快照只读，结果只计算一次
+	cfg.ImageQuality = 200
+	if err := cfg.validateOnce(); err != nil {
+		t.Fatalf("validateOnce() = %v, want the first result", err)
+	}
+
+	// 复制的配置不是快照，每次都校验
+	c := cfg.Clone()
+	if c.validation != nil {
+		t.Fatal("Clone() kept the snapshot validation")
+	}
+	if err := c.validateOnce(); err == nil {
+		t.Fatal("validateOnce() of a copy did not validate")
+	}
+}
@@ -0,0 +1,8 @@
+package resources
+
+import "embed"
+
+// Images 内置的默认背景图，随模块编译进二进制，无需网络访问
+//
+//go:embed images/*.jpg
+var Images embed.FS
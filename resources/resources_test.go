@@ -0,0 +1,29 @@
+package resources
+
+import (
+	"image"
+	_ "image/jpeg"
+	"io/fs"
+	"testing"
+)
+
+func TestImages(t *testing.T) {
+	files, err := fs.Glob(Images, "images/*.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no embedded background images")
+	}
+	for _, file := range files {
+		f, err := Images.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = image.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
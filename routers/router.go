@@ -10,4 +10,6 @@ func InitCaptcha(app foundation.Application) {
 	captchaController := controllers.NewCaptchaController()
 	route.Get("api/captcha", captchaController.GetCaptcha)
 	route.Post("api/captcha", captchaController.PostCaptcha)
+	route.Get("api/captcha/{profile}", captchaController.GetCaptcha)
+	route.Post("api/captcha/{profile}", captchaController.PostCaptcha)
 }
@@ -1,7 +1,19 @@
 package captcha
 
 import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goravel/framework/contracts/config"
 	"github.com/goravel/framework/contracts/foundation"
+	"github.com/hulutech-web/goravel-captcha/instance"
 	"github.com/hulutech-web/goravel-captcha/routers"
 )
 
@@ -26,4 +38,182 @@ func (receiver *ServiceProvider) Boot(app foundation.Application) {
 	})
 	//初始化路由
 	routers.InitCaptcha(app)
+	config := app.MakeConfig()
+	capt := instance.InitCaptcha()
+	//字形缺失的处理方式，先于字符集设置，严格模式下字符集中缺失的字符会被报告
+	if config.GetString("captcha.glyph_coverage") == "strict" {
+		if err := capt.SetGlyphCoverage(instance.CoverageStrict); err != nil {
+			log.Println(err)
+		}
+	}
+	//内置字符集
+	if charset := config.GetString("captcha.charset"); charset != "" {
+		if err := capt.SetCharset(strings.Split(charset, ",")...); err != nil {
+			log.Println(err)
+		}
+	}
+	//词语模式及图标模式
+	switch config.GetString("captcha.text_mode") {
+	case "phrase":
+		var err error
+		if file := config.GetString("captcha.phrase_file"); file != "" {
+			err = capt.SetPhraseFile(file)
+		} else {
+			err = capt.SetTextMode(instance.TextModePhrase)
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	case "icon":
+		if err := capt.SetIconDir(config.GetString("captcha.icon_dir"), config.GetString("captcha.icon_pattern", "*.png")); err != nil {
+			log.Println(err)
+		}
+	}
+	//文本排布方式
+	if config.GetString("captcha.text_placement") == "saliency" {
+		if err := capt.SetTextPlacement(instance.PlacementSaliency); err != nil {
+			log.Println(err)
+		}
+	}
+	//文本与背景的最低对比度
+	if contrast, _ := strconv.ParseFloat(config.GetString("captcha.text_contrast"), 64); contrast != 0 {
+		if err := capt.SetTextContrast(contrast); err != nil {
+			log.Println(err)
+		}
+	}
+	//配色
+	if theme := config.GetString("captcha.theme"); theme != "" && theme != instance.ThemeDefault {
+		if err := capt.SetTheme(theme); err != nil {
+			log.Println(err)
+		}
+	}
+	if err := instance.AllowThemes(strings.Split(config.GetString("captcha.themes"), ",")...); err != nil {
+		log.Println(err)
+	}
+	//主图干扰层
+	alpha, _ := strconv.ParseFloat(config.GetString("captcha.interference.alpha", "0.8"), 64)
+	if err := errors.Join(
+		capt.SetImageWaveLineNum(config.GetInt("captcha.interference.wave_lines")),
+		capt.SetImageArcNum(config.GetInt("captcha.interference.arcs")),
+		capt.SetImageNoiseDotNum(config.GetInt("captcha.interference.noise_dots")),
+		capt.SetImageDecoyStrokeNum(config.GetInt("captcha.interference.decoy_strokes")),
+		capt.SetImageInterferenceAlpha(alpha),
+	); err != nil {
+		log.Println(err)
+	}
+	//本地背景图目录
+	if dir := config.GetString("captcha.background_dir"); dir != "" {
+		if err := capt.SetBackgroundDir(dir, config.GetString("captcha.background_pattern", "*.jpg")); err != nil {
+			log.Println(err)
+		}
+	}
+	//程序生成的背景
+	switch config.GetString("captcha.background_mode", "photo") {
+	case "procedural":
+		if err := capt.SetProceduralBackground(); err != nil {
+			log.Println(err)
+		}
+	case "mixed":
+		cfg := capt.Config()
+		cfg.RangBackground = append(cfg.RangBackground, instance.ProceduralBackgrounds()...)
+		if err := capt.SetConfig(cfg); err != nil {
+			log.Println(err)
+		}
+	}
+	//背景增强
+	if augment, _ := strconv.ParseFloat(config.GetString("captcha.background_augment"), 64); augment != 0 {
+		if err := capt.SetBackgroundAugment(augment); err != nil {
+			log.Println(err)
+		}
+	}
+	//命名配置，在默认配置的基础上修改
+	profiles, _ := config.Get("captcha.profiles").(map[string]any)
+	for name := range profiles {
+		profile, err := instance.NewCaptcha(profileOptions(config, name, capt.Config())...)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		ttl := time.Duration(config.GetInt("captcha.profiles."+name+".ttl", 0)) * time.Second
+		if err := instance.RegisterProfile(name, profile, ttl); err != nil {
+			log.Println(err)
+		}
+	}
+	//自适应难度
+	if config.GetBool("captcha.adaptive.enabled") {
+		halfLife := time.Duration(config.GetInt("captcha.adaptive.half_life", 600)) * time.Second
+		instance.EnableAdaptive(instance.NewFailureTracker(halfLife), nil)
+	}
+	//启动预渲染池
+	if config.GetBool("captcha.pool.enabled") {
+		poolConfig := instance.PoolConfig{
+			Size:    config.GetInt("captcha.pool.size", 50),
+			Workers: config.GetInt("captcha.pool.workers", 2),
+			MaxAge:  time.Duration(config.GetInt("captcha.pool.max_age", 300)) * time.Second,
+		}
+		capt.StartPool(poolConfig)
+		for _, profile := range instance.Profiles() {
+			profile.Captcha.StartPool(poolConfig)
+		}
+		//Goravel 的 ServiceProvider 没有退出阶段，收到退出信号时停止
+		shutdownOnSignal(time.Duration(config.GetInt("captcha.pool.shutdown_timeout", 5)) * time.Second)
+	}
+}
+
+// Shutdown 停止默认配置及命名配置的预渲染池，自行处理退出流程的应用可以直接调用
+func Shutdown(ctx context.Context) error {
+	errs := []error{instance.GetCaptcha().ClosePool(ctx)}
+	for _, profile := range instance.Profiles() {
+		errs = append(errs, profile.Captcha.ClosePool(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// shutdownOnSignal 收到 SIGINT 或 SIGTERM 时停止预渲染池，之后恢复默认处理并重新发送信号，
+// 应用没有自己处理信号时仍按默认方式退出
+func shutdownOnSignal(timeout time.Duration) {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-quit
+		signal.Stop(quit)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := Shutdown(ctx); err != nil {
+			log.Println(err)
+		}
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			_ = p.Signal(sig)
+		}
+	}()
+}
+
+// profileOptions 读取命名配置，未设置的项沿用 base
+func profileOptions(config config.Config, name string, base instance.Config) []instance.Option {
+	prefix := "captcha.profiles." + name + "."
+	opts := []instance.Option{
+		instance.WithConfig(base),
+		instance.WithTextRangLen(instance.RangeVal{
+			Min: config.GetInt(prefix+"text_len_min", base.RangTextLen.Min),
+			Max: config.GetInt(prefix+"text_len_max", base.RangTextLen.Max),
+		}),
+		instance.WithRangCheckTextLen(instance.RangeVal{
+			Min: config.GetInt(prefix+"check_text_len_min", base.RangCheckTextLen.Min),
+			Max: config.GetInt(prefix+"check_text_len_max", base.RangCheckTextLen.Max),
+		}),
+		instance.WithImageFontDistort(config.GetInt(prefix+"image_font_distort", base.ImageFontDistort)),
+		instance.WithImageSize(instance.Size{
+			Width:  config.GetInt(prefix+"image_width", base.ImageSize.Width),
+			Height: config.GetInt(prefix+"image_height", base.ImageSize.Height),
+		}),
+		instance.WithThumbSize(instance.Size{
+			Width:  config.GetInt(prefix+"thumb_width", base.ThumbnailSize.Width),
+			Height: config.GetInt(prefix+"thumb_height", base.ThumbnailSize.Height),
+		}),
+	}
+	if charset := config.GetString(prefix + "charset"); charset != "" {
+		opts = append(opts, instance.WithCharset(strings.Split(charset, ",")...))
+	}
+	return opts
 }